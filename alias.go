@@ -0,0 +1,53 @@
+package flags
+
+import "fmt"
+
+// Alias：注册用户别名，类似git alias，如fs.Alias("st", "status --short")。
+// 解析时若子命令名称未匹配到已注册的子命令，则按别名展开后继续解析，展开结果可包含子命令及参数。
+// 别名展开支持嵌套，但不允许循环展开。
+func (fs *FlagSet) Alias(name, expansion string) *FlagSet {
+	args, err := SplitLine(expansion)
+	if err != nil {
+		panic(fmt.Errorf("flags: invalid alias %v: %w", name, err))
+	}
+	if name == "" || len(args) == 0 {
+		panic(fmt.Errorf("flags: alias name and expansion cannot be empty"))
+	}
+
+	fs.unsealed()
+	f := fs
+	if fs.stmt != nil {
+		f = fs.stmt
+	}
+	if f.aliases == nil {
+		f.aliases = make(map[string][]string)
+	}
+	f.aliases[name] = args
+	return fs
+}
+
+// Aliases：批量注册用户别名，一般从配置文件中加载，见Alias。
+func (fs *FlagSet) Aliases(aliases map[string]string) *FlagSet {
+	for name, expansion := range aliases {
+		fs.Alias(name, expansion)
+	}
+	return fs
+}
+
+// expand：展开别名，将展开结果插入到当前位置。
+func (s *arguments) expand(name string, exp []string) error {
+	if s.expanded[name] {
+		return fmt.Errorf("alias %v: recursive expansion", name)
+	}
+	if s.expanded == nil {
+		s.expanded = make(map[string]bool)
+	}
+	s.expanded[name] = true
+
+	args := make([]string, 0, len(s.args)-s.idx+len(exp))
+	args = append(args, exp...)
+	args = append(args, s.args[s.idx:]...)
+	s.args = args
+	s.idx = 0
+	return nil
+}
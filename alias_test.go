@@ -0,0 +1,38 @@
+package flags
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAlias(t *testing.T) {
+	fs := New("alias", "")
+	status := fs.Cmd("status", "")
+	short := status.Bool('s', "short", false, "short format")
+	var run bool
+	status.Handle(func(context.Context) { run = true })
+
+	fs.Aliases(map[string]string{
+		"st":   "status --short",
+		"stat": "st",
+		"loop": "loop1",
+	})
+	fs.Alias("loop1", "loop")
+
+	for _, name := range []string{"st", "stat"} {
+		fs.Reset()
+		run = false
+		_, err := fs.Run(context.Background(), name)
+		if err != nil {
+			t.Fatalf("alias %v run: %v", name, err)
+		}
+		if !run || !*short {
+			t.Fatalf("alias %v result: run %v, short %v", name, run, *short)
+		}
+	}
+
+	_, err := fs.Run(context.Background(), "loop")
+	if err == nil {
+		t.Fatalf("alias loop: no err")
+	}
+}
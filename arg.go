@@ -0,0 +1,209 @@
+package flags
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ArgVar：注册位置参数，即命令之后不以"-"开头的参数，按注册顺序依次解析到ptr，解析方式与AnyVar相同。
+// 位置参数与子命令或别名同名时，优先作为子命令或别名。未给出的位置参数使用默认值dft。
+// 位置参数仅属于当前命令，不会被子命令继承，Usage中显示在Arguments部分。
+func (fs *FlagSet) ArgVar(ptr any, name string, dft any, desc string) {
+	fs.unsealed()
+	if name == "" {
+		panic(fmt.Errorf("flags: argument name cannot be empty"))
+	}
+	for _, p := range fs.posArgs {
+		if p.arg == name {
+			panic(fmt.Errorf("flags: duplicated argument: %v", name))
+		}
+	}
+
+	p := newVar(ptr, dft)
+	p.arg = name
+	p.desc = desc
+	fs.posArgs = append(fs.posArgs, p)
+	fs.touch()
+}
+
+// Arg：注册位置参数，见FlagSet.ArgVar。如：
+//
+//	n := flags.Arg[int](fs, "count", 1, "repeat count")
+//	d := flags.Arg[time.Duration](fs, "timeout", time.Second, "timeout")
+func Arg[T ElemTypes](fs *FlagSet, name string, dft T, desc string) *T {
+	ptr := new(T)
+	fs.ArgVar(ptr, name, dft, desc)
+	return ptr
+}
+
+func ArgVar[T ElemTypes](fs *FlagSet, ptr *T, name string, dft T, desc string) {
+	fs.ArgVar(ptr, name, dft, desc)
+}
+
+// RestVar：注册可变位置参数，在ArgVar注册的位置参数之后，吸收其余所有不以"-"开头的参数，如"app rm FILE..."。
+// 每个参数解析为slice的一个元素，不按分隔符拆分。给出的参数少于min个时解析报错。每个命令只能注册一个可变位置参数。
+// "--"之后的参数均视为位置参数，即使以"-"开头或与子命令同名。
+func (fs *FlagSet) RestVar(ptr any, name string, min int, desc string) {
+	fs.unsealed()
+	if name == "" {
+		panic(fmt.Errorf("flags: argument name cannot be empty"))
+	}
+	if fs.rest != nil {
+		panic(fmt.Errorf("flags: duplicated rest argument: %v", name))
+	}
+	for _, p := range fs.posArgs {
+		if p.arg == name {
+			panic(fmt.Errorf("flags: duplicated argument: %v", name))
+		}
+	}
+
+	p := newVar(ptr, nil)
+	if !p.isSlice() {
+		panic(fmt.Errorf("flags: rest argument type %v must be a slice", p.rtyp))
+	}
+	p.arg = name
+	p.desc = desc
+	fs.rest = p
+	fs.restMin = min
+	fs.touch()
+}
+
+// Rest：注册可变位置参数，见FlagSet.RestVar。如：
+//
+//	files := flags.Rest[string](fs, "FILE", 1, "files to remove")
+func Rest[T ElemTypes](fs *FlagSet, name string, min int, desc string) *[]T {
+	ptr := new([]T)
+	fs.RestVar(ptr, name, min, desc)
+	return ptr
+}
+
+func RestVar[T ElemTypes](fs *FlagSet, ptr *[]T, name string, min int, desc string) {
+	fs.RestVar(ptr, name, min, desc)
+}
+
+// allArgs：所有位置参数，包括可变位置参数。
+func (fs *FlagSet) allArgs() []*param {
+	if fs.rest == nil {
+		return fs.posArgs
+	}
+	return append(fs.posArgs[:len(fs.posArgs):len(fs.posArgs)], fs.rest)
+}
+
+// nextArg：arg作为位置参数时对应的param，arg为子命令或别名，或位置参数均已解析时返回nil。
+func (fs *FlagSet) nextArg(arg string) *param {
+	if fs.subcmd(arg) != nil {
+		return nil
+	}
+	if _, ok := fs.aliases[arg]; ok {
+		return nil
+	}
+	return fs.freeArg()
+}
+
+// freeArg：下一个未解析的位置参数，均已解析时为可变位置参数。
+func (fs *FlagSet) freeArg() *param {
+	for _, p := range fs.posArgs {
+		if !p.parsed {
+			return p
+		}
+	}
+	return fs.rest
+}
+
+// _parseArg：将"--"之后的参数解析为位置参数。
+func (fs *FlagSet) _parseArg(args *arguments, arg string) error {
+	p := fs.freeArg()
+	if p == nil {
+		return fmt.Errorf("%v: unexpected argument: %v", fs.name, arg)
+	}
+	return fs._parsePosArg(args, arg, p)
+}
+
+func (fs *FlagSet) _parsePosArg(args *arguments, arg string, p *param) error {
+	args.positional = append(args.positional, arg)
+	if p.isSlice() {
+		p.parsed = true
+		p.source = SourceFlag
+		if err := fs._parseSlice(newArgs(arg), p.arg, p); err != nil {
+			return err
+		}
+		fs.flagParsed(p.arg, p)
+		return nil
+	}
+	return fs._parseParam(newArg(arg), p.arg, p)
+}
+
+// checkArgs：检查可变位置参数个数，以及ValidateArgs设置的校验。
+func (fs *FlagSet) checkArgs(positional []string) error {
+	if fs.rest != nil {
+		if n := reflect.ValueOf(fs.rest.ptr).Elem().Len(); n < fs.restMin {
+			return fmt.Errorf("%v: requires at least %v %v argument(s), got %v", fs.fullName(), fs.restMin, fs.rest.arg, n)
+		}
+	}
+	if fs.argsV != nil {
+		if err := fs.argsV(positional); err != nil {
+			return fmt.Errorf("%v: %w", fs.fullName(), err)
+		}
+	}
+	return nil
+}
+
+// ArgsValidator：位置参数校验函数，args为命令行中给出的位置参数原始值。
+type ArgsValidator func(args []string) error
+
+// ValidateArgs：设置位置参数校验，解析完成后、执行命令前调用，校验失败时Run返回错误，命令不会执行。如：
+//
+//	fs.ValidateArgs(flags.RangeArgs(1, 2))
+func (fs *FlagSet) ValidateArgs(v ArgsValidator) *FlagSet {
+	fs.unsealed()
+	fs.argsV = v
+	return fs
+}
+
+// NoArgs：不接受位置参数。
+func NoArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("accepts no arguments, received %v", len(args))
+	}
+	return nil
+}
+
+// ExactArgs：位置参数个数必须为n。
+func ExactArgs(n int) ArgsValidator {
+	return func(args []string) error {
+		if len(args) != n {
+			return fmt.Errorf("accepts %v argument(s), received %v", n, len(args))
+		}
+		return nil
+	}
+}
+
+// MinimumNArgs：位置参数个数至少为n。
+func MinimumNArgs(n int) ArgsValidator {
+	return func(args []string) error {
+		if len(args) < n {
+			return fmt.Errorf("requires at least %v argument(s), received %v", n, len(args))
+		}
+		return nil
+	}
+}
+
+// MaximumNArgs：位置参数个数至多为n。
+func MaximumNArgs(n int) ArgsValidator {
+	return func(args []string) error {
+		if len(args) > n {
+			return fmt.Errorf("accepts at most %v argument(s), received %v", n, len(args))
+		}
+		return nil
+	}
+}
+
+// RangeArgs：位置参数个数在[min, max]之间。
+func RangeArgs(min, max int) ArgsValidator {
+	return func(args []string) error {
+		if len(args) < min || len(args) > max {
+			return fmt.Errorf("accepts between %v and %v argument(s), received %v", min, max, len(args))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+package flags
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArg(t *testing.T) {
+	fs := New("arg", "typed arguments")
+	verbose := fs.Bool('v', "verbose", false, "")
+	n := Arg[int](fs, "count", 1, "repeat count")
+	d := Arg[time.Duration](fs, "timeout", time.Second, "timeout")
+	fs.Handle(func(context.Context) {})
+
+	var sub bool
+	fs.Cmd("sub", "").Handle(func(context.Context) { sub = true })
+
+	if _, err := fs.Run(context.Background(), "3", "-v", "5s"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *n != 3 || *d != 5*time.Second || !*verbose {
+		t.Fatalf("count: %v, timeout: %v, verbose: %v", *n, *d, *verbose)
+	}
+
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "2"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *n != 2 || *d != time.Second {
+		t.Fatalf("count: %v, timeout: %v", *n, *d)
+	}
+
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "sub"); err != nil || !sub {
+		t.Fatalf("sub command: %v, %v", sub, err)
+	}
+
+	fs.Reset()
+	_, err := fs.Run(context.Background(), "x")
+	if err == nil || !strings.Contains(err.Error(), "parse option count") {
+		t.Fatalf("invalid count: %v", err)
+	}
+
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "1", "2s", "extra")
+	if err == nil || !strings.Contains(err.Error(), "unknown sub command: extra") {
+		t.Fatalf("extra argument: %v", err)
+	}
+
+	usage := fs.Usage()
+	if !strings.Contains(usage, "arg [option|command] [count] [timeout]") ||
+		!strings.Contains(usage, "Arguments:\n  count int (default: 1)\n    repeat count\n\n  timeout duration (default: 1s)") {
+		t.Fatalf("usage: %v", usage)
+	}
+}
+
+func TestRest(t *testing.T) {
+	fs := New("rm", "remove files")
+	force := fs.Bool('f', "force", false, "")
+	dir := Arg[string](fs, "dir", ".", "base dir")
+	files := Rest[string](fs, "FILE", 1, "files to remove")
+	fs.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "tmp", "a,b", "-f", "c"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *dir != "tmp" || !sliceEqual(*files, "a,b", "c") || !*force {
+		t.Fatalf("dir: %q, files: %q, force: %v", *dir, *files, *force)
+	}
+
+	fs.Reset()
+	_, err := fs.Run(context.Background(), "tmp")
+	if err == nil || !strings.Contains(err.Error(), "requires at least 1 FILE argument(s), got 0") {
+		t.Fatalf("min count: %v", err)
+	}
+
+	fs.Reset()
+	if _, err = fs.Run(context.Background(), "tmp", "--", "-f", "--x"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *force || !sliceEqual(*files, "-f", "--x") {
+		t.Fatalf("files: %q, force: %v", *files, *force)
+	}
+
+	usage := fs.Usage()
+	if !strings.Contains(usage, "rm [option] [dir] FILE...") ||
+		!strings.Contains(usage, "  FILE []string (min: 1)\n    files to remove") {
+		t.Fatalf("usage: %v", usage)
+	}
+
+	fs = New("args", "")
+	Arg[int](fs, "n", 0, "")
+	fs.Handle(func(context.Context) {})
+	_, err = fs.Run(context.Background(), "--", "1", "2")
+	if err == nil || !strings.Contains(err.Error(), "unexpected argument: 2") {
+		t.Fatalf("unexpected argument: %v", err)
+	}
+}
+
+func TestValidateArgs(t *testing.T) {
+	fs := New("cp", "copy files")
+	src := Arg[string](fs, "src", "", "")
+	Arg[string](fs, "dst", "", "")
+	fs.ValidateArgs(ExactArgs(2))
+	var ran bool
+	fs.Handle(func(context.Context) { ran = true })
+
+	_, err := fs.Run(context.Background(), "a")
+	if err == nil || err.Error() != "cp: accepts 2 argument(s), received 1" || ran {
+		t.Fatalf("exact args: %v", err)
+	}
+
+	fs.Reset()
+	if _, err = fs.Run(context.Background(), "a", "b"); err != nil || !ran || *src != "a" {
+		t.Fatalf("run: %v, ran: %v, src: %q", err, ran, *src)
+	}
+
+	for _, c := range []struct {
+		v    ArgsValidator
+		n    int
+		fail bool
+	}{
+		{NoArgs, 0, false},
+		{NoArgs, 1, true},
+		{MinimumNArgs(2), 1, true},
+		{MinimumNArgs(2), 2, false},
+		{MaximumNArgs(1), 2, true},
+		{MaximumNArgs(1), 1, false},
+		{RangeArgs(1, 2), 0, true},
+		{RangeArgs(1, 2), 2, false},
+		{RangeArgs(1, 2), 3, true},
+	} {
+		if err := c.v(make([]string, c.n)); (err != nil) != c.fail {
+			t.Fatalf("validator with %v args: %v", c.n, err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package flags
+
+import "fmt"
+
+// ChainCommands：开启命令串联，一次调用中可用sep分隔多条命令，如`app build ++ test ++ deploy --env prod`。
+// 各条命令均从Run所在的命令开始解析，共享同一组参数变量，因此任意一条命令中出现的全局参数对所有命令生效。
+// 全部解析成功后才按顺序执行各命令的Handler，任一命令出错即停止执行后续命令。
+// 由于参数变量共享，同一命令在一次调用中只能出现一次，如`app deploy -e a ++ deploy -e b`会报错。仅根命令有效。
+func (fs *FlagSet) ChainCommands(sep string) *FlagSet {
+	fs.unsealed()
+	if sep == "" || sep == "--" {
+		panic(fmt.Errorf("flags: chain separator %q: invalid", sep))
+	}
+	fs.root().chainSep = sep
+	return fs
+}
+
+// splitChain：按分隔符拆分参数，未开启串联时原样返回。空命令会被忽略。
+func (fs *FlagSet) splitChain(args []string) [][]string {
+	if fs.chainSep == "" {
+		return [][]string{args}
+	}
+
+	var segs [][]string
+	start := 0
+	for i, arg := range args {
+		if arg == fs.chainSep {
+			if i > start {
+				segs = append(segs, args[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(args) || len(segs) == 0 {
+		segs = append(segs, args[start:])
+	}
+	return segs
+}
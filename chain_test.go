@@ -0,0 +1,68 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChainCommands(t *testing.T) {
+	fs := New("app", "make like")
+	verbose := fs.Bool('v', "verbose", false, "verbose")
+	fs.ChainCommands("++")
+
+	var got []string
+	fs.Cmd("build", "").Handle(func(ctx context.Context) {
+		got = append(got, "build:"+strings.Join(Args(ctx), ","))
+	})
+	fs.Cmd("test", "").HandleE(func(ctx context.Context) error {
+		got = append(got, "test")
+		if *verbose {
+			return nil
+		}
+		return errors.New("test failed")
+	})
+	deploy := fs.Cmd("deploy", "")
+	env := deploy.Str('e', "env", "dev", "environment")
+	deploy.Handle(func(ctx context.Context) {
+		got = append(got, "deploy:"+*env)
+	})
+
+	_, err := fs.Run(context.Background(), "build", "++", "-v", "test", "++", "deploy", "--env", "prod")
+	if err != nil {
+		t.Fatalf("chain run: %v", err)
+	}
+	if !sliceEqual(got, "build:", "test", "deploy:prod") {
+		t.Fatalf("chain run result: %q", got)
+	}
+
+	// stop on first error
+	fs.Reset()
+	got = nil
+	_, err = fs.Run(context.Background(), "build", "++", "test", "++", "deploy")
+	if err == nil || err.Error() != "test failed" {
+		t.Fatalf("chain run error: %v", err)
+	}
+	if !sliceEqual(got, "build:", "test") {
+		t.Fatalf("chain run error result: %q", got)
+	}
+
+	// parse all before exec
+	fs.Reset()
+	got = nil
+	_, err = fs.Run(context.Background(), "build", "++", "deploy", "--unknown")
+	if err == nil || !strings.Contains(err.Error(), "unknown option") {
+		t.Fatalf("chain parse error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("chain parse error result: %q", got)
+	}
+
+	// params are shared, so a command may appear only once
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "deploy", "-e", "a", "++", "build", "++", "deploy", "-e", "b")
+	if err == nil || err.Error() != "app: command app deploy repeated in chain" || len(got) != 0 {
+		t.Fatalf("chain repeated command: %v, %q", err, got)
+	}
+}
@@ -0,0 +1,345 @@
+package flags
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ConfigFormat：配置文件格式
+type ConfigFormat string
+
+const (
+	ConfigJSON ConfigFormat = "json"
+	ConfigYAML ConfigFormat = "yaml"
+	ConfigTOML ConfigFormat = "toml"
+)
+
+// WriteConfigTemplate：根据已注册的参数生成配置文件模板，每个参数对应一个配置项，值为默认值。
+// 包括当前命令可见的参数及各级子命令中注册的参数，同名配置项只保留先出现的一个，与ConfigFiles接受的配置项一致。
+// YAML和TOML格式以注释形式带上参数描述，子命令的参数以命令名称分组；JSON不支持注释，仅包含配置项。
+// 配置项名称为长参数名，没有长参数名时为短参数名。
+func (fs *FlagSet) WriteConfigTemplate(w io.Writer, format ConfigFormat) error {
+	b := new(bytes.Buffer)
+	var err error
+	switch format {
+	case ConfigJSON:
+		err = fs.writeJSONTemplate(b)
+	case ConfigYAML:
+		err = fs.writeCommentTemplate(b, func(key string, val any) (string, error) {
+			s, err := encodeJSON(val)
+			return key + ": " + s, err
+		})
+	case ConfigTOML:
+		err = fs.writeCommentTemplate(b, func(key string, val any) (string, error) {
+			s, err := tomlValue(val)
+			return tomlKey(key) + " = " + s, err
+		})
+	default:
+		return fmt.Errorf("flags: unknown config format: %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// configGroup：配置文件模板中一个命令首次出现的参数。
+type configGroup struct {
+	cmd    *FlagSet
+	params []*param
+}
+
+// configGroups：按命令分组的配置项，依次为当前命令可见的参数，及按注册顺序遍历语句、子命令时新出现的参数。
+// 配置文件模板及ConfigFiles检查未知配置项均以此为准。
+func (fs *FlagSet) configGroups() []configGroup {
+	seen := make(map[string]bool)
+	var groups []configGroup
+	add := func(f *FlagSet, params []*param) {
+		if f.stmt != nil {
+			// 语句紧随所属命令遍历，其参数归入所属命令的分组
+			f = f.stmt
+		}
+		g := configGroup{cmd: f}
+		for _, p := range params {
+			if key := p.configKey(); !seen[key] {
+				seen[key] = true
+				g.params = append(g.params, p)
+			}
+		}
+		if n := len(groups); n > 0 && groups[n-1].cmd == f {
+			groups[n-1].params = append(groups[n-1].params, g.params...)
+		} else if len(g.params) > 0 || f == fs {
+			groups = append(groups, g)
+		}
+	}
+	fs.walk(func(f *FlagSet) {
+		if f == fs {
+			add(f, f.allParams())
+		} else {
+			add(f, f.params)
+		}
+	})
+	return groups
+}
+
+func (fs *FlagSet) writeJSONTemplate(w *bytes.Buffer) error {
+	fmt.Fprintf(w, "{")
+	n := 0
+	for _, g := range fs.configGroups() {
+		for _, p := range g.params {
+			val, err := encodeJSON(configValue(p, p.dftValue()))
+			if err != nil {
+				return fmt.Errorf("flags: config %v: %w", p.configKey(), err)
+			}
+			if n > 0 {
+				fmt.Fprintf(w, ",")
+			}
+			fmt.Fprintf(w, "\n  %v: %v", jsonString(p.configKey()), val)
+			n++
+		}
+	}
+	if n > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "}")
+	return nil
+}
+
+func (fs *FlagSet) writeCommentTemplate(w *bytes.Buffer, line func(key string, val any) (string, error)) error {
+	for i, g := range fs.configGroups() {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "# %v - %v\n", g.cmd.fullName(), g.cmd.desc)
+		for _, p := range g.params {
+			s, err := line(p.configKey(), configValue(p, p.dftValue()))
+			if err != nil {
+				return fmt.Errorf("flags: config %v: %w", p.configKey(), err)
+			}
+			fmt.Fprintln(w)
+			if p.desc != "" {
+				for _, s := range strings.Split(p.desc, "\n") {
+					fmt.Fprintf(w, "# %v\n", s)
+				}
+			}
+			fmt.Fprintln(w, s)
+		}
+	}
+	return nil
+}
+
+// configKey：参数在配置文件中的名称。
+func (p *param) configKey() string {
+	if p.long != "" {
+		return p.long
+	}
+	return p.short
+}
+
+// dftValue：参数默认值，没有默认值（或为延迟计算的默认值）时为零值。
+func (p *param) dftValue() reflect.Value {
+	if p.dft != nil {
+		return reflect.ValueOf(p.dft)
+	}
+	return reflect.Zero(p.rtyp)
+}
+
+// configValue：将参数值转为配置文件中的值，数字和bool保持原样，slice转为[]any，map转为map[string]any，
+// struct转为JSON对象，其它类型转为命令行中的字符串形式。
+func configValue(p *param, v reflect.Value) any {
+	switch p.rtyp {
+	case typDuration, typDateTime, typLocation, typHardwareAddr, typFileMode, typLogLevel, typTemplate:
+		return formatScalar(v)
+	}
+
+	switch p.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		list := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if p.elemPtr {
+				elem = elem.Elem()
+			}
+			list = append(list, configValue(p.elem, elem))
+		}
+		return list
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[formatScalar(iter.Key())] = configValue(p.elem, iter.Value())
+		}
+		return m
+	case reflect.Struct:
+		var m map[string]any
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return v.Interface() // 由调用方编码时报告错误
+		}
+		json.Unmarshal(b, &m)
+		return m
+	}
+	return formatScalar(v)
+}
+
+// formatScalar：将标量值格式化为命令行中的字符串形式。
+func formatScalar(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	switch x := v.Interface().(type) {
+	case time.Duration:
+		return x.String()
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format(DateTime)
+	case *time.Location:
+		if x == nil {
+			return ""
+		}
+		return x.String()
+	case net.HardwareAddr:
+		return x.String()
+	case os.FileMode:
+		return fmt.Sprintf("%#o", uint32(x))
+	case slog.Level:
+		return x.String()
+	case *template.Template:
+		if x == nil || x.Tree == nil {
+			return ""
+		}
+		return x.Root.String()
+	case *big.Rat:
+		return formatDecimal(x)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// formatValue：将参数值格式化为命令行中的字符串形式，slice、map按分隔符拼接，元素中的分隔符会被转义，map按key排序。
+func formatValue(p *param, v reflect.Value) string {
+	switch {
+	case p.isSlice():
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elem := v.Index(i)
+			if p.elemPtr {
+				elem = elem.Elem()
+			}
+			elems[i] = escape(formatValue(p.elem, elem), p.sep1)
+		}
+		return strings.Join(elems, p.sep1)
+	case p.kind == reflect.Map:
+		var pairs []string
+		iter := v.MapRange()
+		for iter.Next() {
+			key := formatScalar(iter.Key())
+			if p.elem.kind != reflect.Map {
+				pairs = append(pairs, escape(key, p.sep1, p.sep2)+p.sep2+escape(formatValue(p.elem, iter.Value()), p.sep1, p.sep2))
+				continue
+			}
+			seps := []string{p.sep1, p.sep2, p.sep3}
+			inner := iter.Value().MapRange()
+			for inner.Next() {
+				ik := formatScalar(inner.Key())
+				pairs = append(pairs, escape(key, seps...)+p.sep3+escape(ik, seps...)+p.sep2+escape(formatValue(p.elem.elem, inner.Value()), seps...))
+			}
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, p.sep1)
+	case p.kind == reflect.Struct && p.rtyp != typDateTime:
+		return jsonValue(v.Interface())
+	}
+	return formatScalar(v)
+}
+
+func jsonString(s string) string {
+	b := new(bytes.Buffer)
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSpace(b.String())
+}
+
+// encodeJSON：将v编码为单行JSON，不转义HTML字符。
+func encodeJSON(v any) (string, error) {
+	b := new(bytes.Buffer)
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// jsonValue：同encodeJSON，用于Trace、Usage等无法返回错误的输出，编码失败时为空字符串。
+func jsonValue(v any) string {
+	s, _ := encodeJSON(v)
+	return s
+}
+
+func tomlKey(key string) string {
+	for i := 0; i < len(key); i++ {
+		if !isNumber(key[i]) && !isLetter(key[i]) && key[i] != '-' && key[i] != '_' {
+			return jsonString(key)
+		}
+	}
+	return key
+}
+
+func tomlValue(v any) (string, error) {
+	switch x := v.(type) {
+	case []any:
+		elems := make([]string, 0, len(x))
+		for _, e := range x {
+			s, err := tomlValue(e)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, s)
+		}
+		return "[" + strings.Join(elems, ", ") + "]", nil
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(x))
+		for _, k := range keys {
+			s, err := tomlValue(x[k])
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, tomlKey(k)+" = "+s)
+		}
+		if len(pairs) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }", nil
+	}
+	return encodeJSON(v)
+}
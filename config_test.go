@@ -0,0 +1,139 @@
+package flags
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteConfigTemplate(t *testing.T) {
+	fs := New("config", "config template")
+	fs.Str('o', "output", "out.txt", "output file")
+	fs.Int('n', NoLong, 3, "count")
+	fs.Duration(NoShort, "timeout", time.Second, "timeout\nof request")
+	Slice[string](fs, NoShort, "tags", []string{"a", "b"}, "")
+	Map[string, int](fs, NoShort, "limits.max", map[string]int{"cpu": 2}, "limits")
+
+	b := new(bytes.Buffer)
+	if err := fs.WriteConfigTemplate(b, ConfigJSON); err != nil {
+		t.Fatalf("write json template: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatalf("json template %s: %v", b, err)
+	}
+	if m["output"] != "out.txt" || m["n"] != 3.0 || m["timeout"] != "1s" {
+		t.Fatalf("json template: %s", b)
+	}
+
+	b.Reset()
+	if err := fs.WriteConfigTemplate(b, ConfigYAML); err != nil {
+		t.Fatalf("write yaml template: %v", err)
+	}
+	yaml := `# config - config template
+
+# output file
+output: "out.txt"
+
+# count
+n: 3
+
+# timeout
+# of request
+timeout: "1s"
+
+tags: ["a","b"]
+
+# limits
+limits.max: {"cpu":2}
+`
+	if b.String() != yaml {
+		t.Fatalf("yaml template:\n%s", b)
+	}
+
+	b.Reset()
+	if err := fs.WriteConfigTemplate(b, ConfigTOML); err != nil {
+		t.Fatalf("write toml template: %v", err)
+	}
+	toml := `# config - config template
+
+# output file
+output = "out.txt"
+
+# count
+n = 3
+
+# timeout
+# of request
+timeout = "1s"
+
+tags = ["a", "b"]
+
+# limits
+"limits.max" = { cpu = 2 }
+`
+	if b.String() != toml {
+		t.Fatalf("toml template:\n%s", b)
+	}
+
+	if err := fs.WriteConfigTemplate(b, "ini"); err == nil {
+		t.Fatalf("write ini template: no err")
+	}
+}
+
+func TestWriteConfigTemplateTree(t *testing.T) {
+	fs := New("app", "app desc")
+	fs.Str('o', "output", "out.txt", "output file")
+	build := fs.Cmd("build", "build desc")
+	build.Int('j', "jobs", 4, "parallel jobs")
+	build.Str('o', "output", "bin", "") // shadows the root option: same config key
+	fs.Cmd("test", "test desc").Cmd("unit", "unit desc").Bool(0, "race", false, "")
+
+	// flags registered only on subcommands are part of the template, grouped by command
+	b := new(bytes.Buffer)
+	if err := fs.WriteConfigTemplate(b, ConfigTOML); err != nil {
+		t.Fatalf("write toml template: %v", err)
+	}
+	toml := `# app - app desc
+
+# output file
+output = "out.txt"
+
+# app build - build desc
+
+# parallel jobs
+jobs = 4
+
+# app test unit - unit desc
+
+race = false
+`
+	if b.String() != toml {
+		t.Fatalf("toml template:\n%s", b)
+	}
+
+	b.Reset()
+	if err := fs.WriteConfigTemplate(b, ConfigJSON); err != nil || b.String() != "{\n  \"output\": \"out.txt\",\n  \"jobs\": 4,\n  \"race\": false\n}\n" {
+		t.Fatalf("json template: %v\n%s", err, b)
+	}
+
+	// flags registered on statements are part of the template
+	st := New("app", "")
+	st.Int('n', "num", 1, "")
+	st.Stmt().Str(0, "token", "", "")
+	b.Reset()
+	if err := st.WriteConfigTemplate(b, ConfigJSON); err != nil || b.String() != "{\n  \"num\": 1,\n  \"token\": \"\"\n}\n" {
+		t.Fatalf("stmt json template: %v\n%s", err, b)
+	}
+
+	// encoding errors are returned
+	type bad struct{ C chan int }
+	fs.AnyVar(new(bad), 0, "bad", bad{C: make(chan int)}, "")
+	for _, format := range []ConfigFormat{ConfigJSON, ConfigYAML, ConfigTOML} {
+		if err := fs.WriteConfigTemplate(b, format); err == nil || !strings.Contains(err.Error(), "config bad:") {
+			t.Fatalf("write %v template: %v", format, err)
+		}
+	}
+}
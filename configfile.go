@@ -0,0 +1,365 @@
+package flags
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ConfigFiles：设置配置文件路径，按优先级从高到低排列。解析时读取所有存在的文件，同名配置项以优先级高的文件为准，
+// 参数值优先级为：命令行参数 > 环境变量 > 配置文件 > 默认值。配置项名称为长参数名，没有长参数名时为短参数名，
+// 与WriteConfigTemplate生成的模板相同，对所有可见该参数的命令生效。
+// 按扩展名判断格式：.yaml、.yml为YAML，.toml为TOML，其它为JSON。YAML、TOML仅支持WriteConfigTemplate
+// 及--print-config输出的单层"key: value"、"key = value"形式，值可为单行的数组及行内表。
+// 每次Run、Parse时重新读取。不对应任何已注册参数的配置项（如拼写错误）作为警告报告，见FlagSet.StrictConfig。
+// 仅根命令有效。
+func (fs *FlagSet) ConfigFiles(paths ...string) *FlagSet {
+	fs.unsealed()
+	root := fs.root()
+	root.configPaths = append(root.configPaths, paths...)
+	return fs
+}
+
+// configEntry：配置项的值及所在文件。
+type configEntry struct {
+	val  any
+	file string
+}
+
+// loadConfig：读取配置文件，保存到根命令，供setDft使用。
+func (fs *FlagSet) loadConfig() error {
+	root := fs.root()
+	root.configVals = nil
+	root.configStamp = nil
+	paths := root.ConfigPaths()
+	if len(paths) == 0 {
+		return nil
+	}
+	// 先记录文件状态再读取，读取期间的修改可被WatchConfig发现
+	root.configStamp = statConfig(paths)
+
+	vals := make(map[string]configEntry)
+	for i := len(paths) - 1; i >= 0; i-- {
+		m, err := readConfigFile(paths[i])
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%v: config file %v: %w", root.name, paths[i], err)
+		}
+		if root.tracing() {
+			root.tracef("config %v", paths[i])
+		}
+		for key, val := range m {
+			vals[key] = configEntry{val: val, file: paths[i]}
+		}
+	}
+	root.configVals = vals
+	return root.checkConfigKeys(vals)
+}
+
+// StrictConfig：配置文件中出现不对应任何已注册参数的配置项时报错，错误可用errors.Is(err, ErrUnknownOption)判断，
+// 而不是仅作为警告报告。仅根命令有效。
+func (fs *FlagSet) StrictConfig() *FlagSet {
+	fs.unsealed()
+	fs.root().strictConfig = true
+	return fs
+}
+
+// checkConfigKeys：检查配置项是否对应命令树中某个参数，未知配置项附带相近的参数名作为提示。
+// 配置项对所有命令生效，因此只要有一个命令注册了该参数即可。
+func (fs *FlagSet) checkConfigKeys(vals map[string]configEntry) error {
+	known := make(map[string]bool)
+	for _, g := range fs.configGroups() {
+		for _, p := range g.params {
+			known[p.configKey()] = true
+		}
+	}
+	var unknown []string
+	for key := range vals {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	names := make([]string, 0, len(known))
+	for key := range known {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	for _, key := range unknown {
+		var hint string
+		if s := suggest(key, names); s != "" {
+			hint = fmt.Sprintf(", did you mean %q?", s)
+		}
+		file := vals[key].file
+		if fs.strictConfig {
+			return fmt.Errorf("%v: config file %v: %w: %v%v", fs.name, file, ErrUnknownOption, key, hint)
+		}
+		fs.notice("", "config file %v: unknown key %q%v", file, key, hint)
+	}
+	return nil
+}
+
+// suggest：names中与name编辑距离最小的一个，距离超过name长度的三分之一（至少为1）时认为不相近，返回空。
+func suggest(name string, names []string) string {
+	best, dist := "", max(len(name)/3, 1)+1
+	for _, s := range names {
+		if d := editDistance(name, s); d < dist {
+			best, dist = s, d
+		}
+	}
+	return best
+}
+
+// editDistance：两个字符串间的编辑距离（Levenshtein距离）。
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// readConfigFile：读取配置文件，返回各配置项的值，值为JSON形式，见FlagSet.parseAny。
+func readConfigFile(path string) (map[string]any, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseFlatConfig(string(b), ':')
+	case ".toml":
+		return parseFlatConfig(string(b), '=')
+	}
+	var m map[string]any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err = dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseFlatConfig：解析单层的YAML（sep为':'）或TOML（sep为'='）配置，"#"开始的部分为注释。
+func parseFlatConfig(s string, sep byte) (map[string]any, error) {
+	m := make(map[string]any)
+	for n, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line == "---" {
+			continue
+		}
+		if sep == '=' && line[0] == '[' {
+			return nil, fmt.Errorf("line %v: tables are not supported", n+1)
+		}
+		c := &configParser{s: line, sep: sep}
+		key, err := c.key()
+		if err == nil {
+			err = c.expect(sep)
+		}
+		var val any
+		if err == nil {
+			val, err = c.value(true)
+		}
+		if err == nil {
+			err = c.end()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", n+1, err)
+		}
+		m[key] = val
+	}
+	return m, nil
+}
+
+// configParser：解析一行配置，值可为字符串、数字、bool、数组及行内表，未加引号的值作为字符串。
+type configParser struct {
+	s   string
+	i   int
+	sep byte // 键值分隔符，YAML为':'，TOML为'='
+}
+
+func (c *configParser) skipSpace() {
+	for c.i < len(c.s) && (c.s[c.i] == ' ' || c.s[c.i] == '\t') {
+		c.i++
+	}
+}
+
+func (c *configParser) expect(b byte) error {
+	c.skipSpace()
+	if c.i >= len(c.s) || c.s[c.i] != b {
+		return c.unexpected(fmt.Sprintf("%q", b))
+	}
+	c.i++
+	return nil
+}
+
+func (c *configParser) unexpected(want string) error {
+	if c.i >= len(c.s) {
+		return fmt.Errorf("unexpected end of line, want %v", want)
+	}
+	return fmt.Errorf("unexpected %q at column %v, want %v", c.s[c.i], c.i+1, want)
+}
+
+// end：值之后只能有注释。
+func (c *configParser) end() error {
+	c.skipSpace()
+	if c.i < len(c.s) && c.s[c.i] != '#' {
+		return c.unexpected("end of line")
+	}
+	return nil
+}
+
+func (c *configParser) key() (string, error) {
+	c.skipSpace()
+	if c.i < len(c.s) && (c.s[c.i] == '"' || c.s[c.i] == '\'') {
+		return c.quoted()
+	}
+	start := c.i
+	for c.i < len(c.s) && c.s[c.i] != c.sep && c.s[c.i] != ' ' && c.s[c.i] != '\t' {
+		c.i++
+	}
+	if c.i == start {
+		return "", c.unexpected("key")
+	}
+	return c.s[start:c.i], nil
+}
+
+func (c *configParser) quoted() (string, error) {
+	q := c.s[c.i]
+	start := c.i
+	for c.i++; c.i < len(c.s); c.i++ {
+		switch c.s[c.i] {
+		case '\\':
+			if q == '"' {
+				c.i++
+			}
+		case q:
+			c.i++
+			if q == '\'' {
+				return c.s[start+1 : c.i-1], nil
+			}
+			var str string
+			err := json.Unmarshal([]byte(c.s[start:c.i]), &str)
+			return str, err
+		}
+	}
+	return "", fmt.Errorf("unterminated string at column %v", start+1)
+}
+
+// value：解析一个值，top表示是否为顶层值，顶层未加引号的值可包含","等字符，直到行尾或注释。
+func (c *configParser) value(top bool) (any, error) {
+	c.skipSpace()
+	if c.i >= len(c.s) {
+		return nil, c.unexpected("value")
+	}
+	switch c.s[c.i] {
+	case '"', '\'':
+		return c.quoted()
+	case '[':
+		return c.array()
+	case '{':
+		return c.table()
+	}
+
+	start := c.i
+	for c.i < len(c.s) {
+		b := c.s[c.i]
+		if b == '#' && c.i > start && (c.s[c.i-1] == ' ' || c.s[c.i-1] == '\t') {
+			break
+		}
+		if !top && (b == ',' || b == ']' || b == '}') {
+			break
+		}
+		c.i++
+	}
+	return plainValue(strings.TrimSpace(c.s[start:c.i])), nil
+}
+
+func (c *configParser) array() (any, error) {
+	c.i++ // '['
+	list := []any{}
+	for {
+		c.skipSpace()
+		if c.i < len(c.s) && c.s[c.i] == ']' {
+			c.i++
+			return list, nil
+		}
+		v, err := c.value(false)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		c.skipSpace()
+		if c.i < len(c.s) && c.s[c.i] == ',' {
+			c.i++
+		} else if err = c.expect(']'); err != nil {
+			return nil, err
+		} else {
+			return list, nil
+		}
+	}
+}
+
+func (c *configParser) table() (any, error) {
+	c.i++ // '{'
+	m := map[string]any{}
+	for {
+		c.skipSpace()
+		if c.i < len(c.s) && c.s[c.i] == '}' {
+			c.i++
+			return m, nil
+		}
+		key, err := c.key()
+		if err != nil {
+			return nil, err
+		}
+		if err = c.expect(c.sep); err != nil {
+			return nil, err
+		}
+		if m[key], err = c.value(false); err != nil {
+			return nil, err
+		}
+		c.skipSpace()
+		if c.i < len(c.s) && c.s[c.i] == ',' {
+			c.i++
+		} else if err = c.expect('}'); err != nil {
+			return nil, err
+		} else {
+			return m, nil
+		}
+	}
+}
+
+// plainValue：未加引号的值，bool及数字按JSON形式返回，以便struct参数按JSON解析，其它作为字符串。
+func plainValue(s string) any {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if s != "" && strings.IndexByte("-0123456789", s[0]) >= 0 && json.Valid([]byte(s)) {
+		return json.Number(s)
+	}
+	return s
+}
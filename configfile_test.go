@@ -0,0 +1,148 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %v: %v", path, err)
+	}
+}
+
+func TestConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.toml")
+	system := filepath.Join(dir, "system.json")
+	writeTestFile(t, user, `# user config
+output = "user.txt" # comment
+"limits" = { cpu = 4, mem = "1g" }
+tags = ["x", "y,z"]
+`)
+	writeTestFile(t, system, `{"output": "system.txt", "timeout": "3s", "level": 2, "n": 9, "point": {"X": 1}, "bs": [false, true]}`)
+
+	type point struct{ X int }
+	fs := New("app", "").ConfigFiles(user, filepath.Join(dir, "missing.yaml"), system)
+	output := fs.Str('o', "output", "out.txt", "")
+	timeout := fs.Duration(0, "timeout", time.Second, "")
+	level := fs.Int(0, "level", 1, "")
+	fs.Flag("level").Env("APP_LEVEL")
+	n := fs.Int('n', NoLong, 1, "")
+	tags := Slice[string](fs, 't', "tags", nil, "")
+	limits := Map[string, string](fs, 0, "limits", nil, "")
+	bs := Slice[bool](fs, 0, "bs", nil, "")
+	var pt point
+	fs.AnyVar(&pt, 0, "point", nil, "")
+	fs.SetLookupEnv(func(key string) (string, bool) { return "5", key == "APP_LEVEL" })
+	fs.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "-n", "3"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	// flag > env > config (higher precedence file first) > default
+	if *output != "user.txt" || *timeout != 3*time.Second || *level != 5 || *n != 3 || pt.X != 1 {
+		t.Fatalf("output: %v, timeout: %v, level: %v, n: %v, point: %v", *output, *timeout, *level, *n, pt)
+	}
+	if !sliceEqual(*tags, "x", "y,z") || !reflect.DeepEqual(*limits, map[string]string{"cpu": "4", "mem": "1g"}) {
+		t.Fatalf("tags: %q, limits: %v", *tags, *limits)
+	}
+	if !reflect.DeepEqual(*bs, []bool{false, true}) {
+		t.Fatalf("bs: %v", *bs)
+	}
+	if src := fs.Flag("output").Source(); src != SourceConfig || src.String() != "config" {
+		t.Fatalf("output source: %v", src)
+	}
+
+	writeTestFile(t, user, "output = [1\n")
+	fs.Reset()
+	if _, err := fs.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "user.toml: line 1:") {
+		t.Fatalf("invalid config: %v", err)
+	}
+}
+
+func TestConfigTemplateRoundTrip(t *testing.T) {
+	for _, format := range []ConfigFormat{ConfigJSON, ConfigYAML, ConfigTOML} {
+		src := New("app", "")
+		src.Str('o', "output", "a # b", "output\nfile")
+		src.Float64(0, "ratio", 0.5, "")
+		src.Bool(0, "dry-run", true, "")
+		Slice[string](src, 0, "tags", []string{"a", "b c"}, "")
+		Map[string, int](src, 0, "limits.max", map[string]int{"cpu": 2}, "")
+		b := new(bytes.Buffer)
+		if err := src.WriteConfigTemplate(b, format); err != nil {
+			t.Fatalf("write %v template: %v", format, err)
+		}
+		path := filepath.Join(t.TempDir(), "config."+string(format))
+		writeTestFile(t, path, b.String())
+
+		fs := New("app", "").ConfigFiles(path)
+		output := fs.Str('o', "output", "", "")
+		ratio := fs.Float64(0, "ratio", 0, "")
+		dryRun := fs.Bool(0, "dry-run", false, "")
+		tags := Slice[string](fs, 0, "tags", nil, "")
+		limits := Map[string, int](fs, 0, "limits.max", nil, "")
+		if _, err := fs.Parse(); err != nil {
+			t.Fatalf("parse %v config: %v", format, err)
+		}
+		if *output != "a # b" || *ratio != 0.5 || !*dryRun || !sliceEqual(*tags, "a", "b c") || (*limits)["cpu"] != 2 {
+			t.Fatalf("%v config:\n%s\noutput: %q, ratio: %v, dry-run: %v, tags: %q, limits: %v",
+				format, b, *output, *ratio, *dryRun, *tags, *limits)
+		}
+	}
+}
+
+func TestConfigKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeTestFile(t, path, "outptu: x.txt\ntags: [a]\nzzz: 1\n")
+
+	fs := New("app", "").ConfigFiles(path)
+	var warnings []string
+	fs.OnWarning(func(w Warning) { warnings = append(warnings, w.String()) })
+	fs.Str('o', "output", "", "")
+	build := fs.Cmd("build", "")
+	Slice[string](build, 't', "tags", nil, "") // known: registered by a subcommand
+	build.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "build"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	exp := []string{
+		"app: config file " + path + `: unknown key "outptu", did you mean "output"?`,
+		"app: config file " + path + `: unknown key "zzz"`,
+	}
+	if !sliceEqual(warnings, exp...) {
+		t.Fatalf("warnings: %q", warnings)
+	}
+
+	fs.StrictConfig()
+	fs.Reset()
+	_, err := fs.Run(context.Background(), "build")
+	if !errors.Is(err, ErrUnknownOption) || err.Error() != "app: config file "+path+`: unknown option: outptu, did you mean "output"?` {
+		t.Fatalf("strict config: %v", err)
+	}
+}
+
+func TestConfigKeysStmt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeTestFile(t, path, `{"token": "abc"}`)
+
+	fs := New("app", "").ConfigFiles(path).StrictConfig()
+	s := fs.Stmt()
+	token := s.Str(0, "token", "", "") // known: registered on a statement
+	deploy := s.Cmd("deploy", "")
+	deploy.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "deploy"); err != nil || *token != "abc" {
+		t.Fatalf("run: %v, token: %q", err, *token)
+	}
+}
@@ -0,0 +1,80 @@
+package flags
+
+import (
+	"fmt"
+	"math/big"
+)
+
+func (fs *FlagSet) Decimal(short rune, long string, dft *big.Rat, desc string) **big.Rat {
+	ptr := new(*big.Rat)
+	fs.DecimalVar(ptr, short, long, dft, desc)
+	return ptr
+}
+
+// DecimalVar：十进制定点数参数，以*big.Rat精确保存，如"--price 19.99"，适用于金额等不能有浮点误差的参数。
+// 仅支持可选符号、数字及小数点形式，不支持指数及分数形式。每次赋值均为新的*big.Rat，修改结果不影响默认值。
+func (fs *FlagSet) DecimalVar(ptr **big.Rat, short rune, long string, dft *big.Rat, desc string) {
+	p := addTyped(fs, ptr, short, long, dft, desc, parseDecimal)
+	p.typ = "decimal"
+	if p.setDft != nil {
+		p.setDft = func(ptr any) { *ptr.(**big.Rat) = new(big.Rat).Set(dft) }
+	}
+}
+
+func parseDecimal(s string) (*big.Rat, error) {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits, dot := 0, false
+	for ; i < len(s); i++ {
+		switch {
+		case isNumber(s[i]):
+			digits++
+		case s[i] == '.' && !dot:
+			dot = true
+		default:
+			return nil, fmt.Errorf("invalid decimal: %q", s)
+		}
+	}
+	if digits == 0 {
+		return nil, fmt.Errorf("invalid decimal: %q", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal: %q", s)
+	}
+	return r, nil
+}
+
+// formatDecimal：将r格式化为精确的十进制小数，无法精确表示时以分数形式表示。
+func formatDecimal(r *big.Rat) string {
+	if r == nil {
+		return ""
+	}
+	if r.IsInt() {
+		return r.RatString()
+	}
+
+	var (
+		d    = new(big.Int).Set(r.Denom())
+		m    = new(big.Int)
+		n2   int
+		n5   int
+		two  = big.NewInt(2)
+		five = big.NewInt(5)
+	)
+	for m.Mod(d, two).Sign() == 0 {
+		d.Quo(d, two)
+		n2++
+	}
+	for m.Mod(d, five).Sign() == 0 {
+		d.Quo(d, five)
+		n5++
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return r.RatString()
+	}
+	return r.FloatString(max(n2, n5))
+}
@@ -0,0 +1,64 @@
+package flags
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestDecimal(t *testing.T) {
+	fs := New("decimal", "")
+	price := fs.Decimal('p', "price", big.NewRat(1999, 100), "price")
+	fs.Handle(func(context.Context) {})
+
+	cases := []struct {
+		args []string
+		exp  string
+	}{
+		{nil, "19.99"},
+		{[]string{"-p", "0.1"}, "0.1"},
+		{[]string{"--price=-12.345"}, "-12.345"},
+		{[]string{"-p", "100"}, "100"},
+		{[]string{"-p", "+.5"}, "0.5"},
+	}
+	for _, c := range cases {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), c.args...)
+		if err != nil {
+			t.Fatalf("decimal run %q: %v", c.args, err)
+		}
+		if got := formatDecimal(*price); got != c.exp {
+			t.Fatalf("decimal run %q result: %v", c.args, got)
+		}
+	}
+
+	// exact: 0.1 + 0.2 == 0.3
+	fs.Reset()
+	fs.Run(context.Background(), "-p", "0.1")
+	sum := new(big.Rat).Add(*price, big.NewRat(2, 10))
+	if sum.Cmp(big.NewRat(3, 10)) != 0 {
+		t.Fatalf("decimal sum: %v", sum)
+	}
+
+	// default not shared
+	fs.Reset()
+	fs.Run(context.Background())
+	(*price).SetInt64(0)
+	fs.Reset()
+	fs.Run(context.Background())
+	if got := formatDecimal(*price); got != "19.99" {
+		t.Fatalf("decimal default modified: %v", got)
+	}
+
+	for _, val := range []string{"", "1/3", "1e3", "1.2.3", "abc", "-", "."} {
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), "--price="+val); err == nil {
+			t.Fatalf("decimal run %q: no err", val)
+		}
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, "-p, --price decimal (default: 19.99)") {
+		t.Fatalf("decimal usage: %v", usage)
+	}
+}
@@ -0,0 +1,26 @@
+package flags
+
+// Deprecate：将命令标记为弃用，hint为替代说明，如`use "app remote add" instead`，可为空。
+// 执行该命令时产生警告（见OnWarning），Usage中该命令标注为deprecated，便于平滑地重命名命令。
+func (fs *FlagSet) Deprecate(hint string) *FlagSet {
+	fs.unsealed()
+	fs.deprecated = &hint
+	fs.touch()
+	return fs
+}
+
+// Deprecated：将参数标记为弃用，hint为替代说明，如"use --output instead"，可为空。
+// 命令行中使用该参数时产生警告（见OnWarning），Usage中该参数标注为deprecated。
+func (f *Flag) Deprecated(hint string) *Flag {
+	f.fs.unsealed()
+	f.p.deprecated = &hint
+	f.fs.touch()
+	return f
+}
+
+func deprecatedHint(hint string) string {
+	if hint == "" {
+		return ""
+	}
+	return ": " + hint
+}
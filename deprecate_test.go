@@ -0,0 +1,40 @@
+package flags
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDeprecate(t *testing.T) {
+	fs := New("app", "deprecate")
+	var ran bool
+	fs.Cmd("old", "old name").Deprecate(`use "app new" instead`).Handle(func(context.Context) { ran = true })
+	fs.Cmd("new", "new name").Handle(func(context.Context) {})
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	_, err = fs.Run(context.Background(), "old")
+	os.Stderr = stderr
+	w.Close()
+	if err != nil || !ran {
+		t.Fatalf("deprecated run: %v, ran: %v", err, ran)
+	}
+	out, _ := io.ReadAll(r)
+	if string(out) != "warning: app old: command is deprecated: use \"app new\" instead\n" {
+		t.Fatalf("deprecated warning: %q", out)
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, "  old (deprecated: use \"app new\" instead)\n    old name") {
+		t.Fatalf("deprecated usage: %v", usage)
+	}
+	if usage := fs.Command("old").Usage(); !strings.Contains(usage, "Deprecated: use \"app new\" instead") {
+		t.Fatalf("deprecated command usage: %v", usage)
+	}
+}
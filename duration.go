@@ -0,0 +1,128 @@
+package flags
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDuration：在time.ParseDuration的基础上，支持"d"（天，24h）及"w"（周，7d）单位，如"1d12h"、"2w"。
+func ParseDuration(s string) (time.Duration, error) {
+	if !strings.ContainsAny(s, "dw") {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %q", s)
+		}
+		return d, nil
+	}
+
+	orig := s
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration: %q", orig)
+	}
+
+	var (
+		dur  time.Duration
+		rest strings.Builder // 交给time.ParseDuration解析的部分
+	)
+	add := func(d time.Duration) error {
+		if d > math.MaxInt64-dur {
+			return fmt.Errorf("invalid duration: %q: %w", orig, strconv.ErrRange)
+		}
+		dur += d
+		return nil
+	}
+	for s != "" {
+		i := 0
+		for i < len(s) && (s[i] == '.' || isNumber(s[i])) {
+			i++
+		}
+		j := i
+		for j < len(s) && s[j] != '.' && !isNumber(s[j]) {
+			j++
+		}
+		num, unit := s[:i], s[i:j]
+		s = s[j:]
+
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration: %q", orig)
+			}
+			u := 24 * time.Hour
+			if unit == "w" {
+				u *= 7
+			}
+			if f >= math.MaxInt64/float64(u) {
+				return 0, fmt.Errorf("invalid duration: %q: %w", orig, strconv.ErrRange)
+			}
+			if err = add(time.Duration(f * float64(u))); err != nil {
+				return 0, err
+			}
+		default:
+			rest.WriteString(num)
+			rest.WriteString(unit)
+		}
+	}
+	if rest.Len() > 0 {
+		d, err := time.ParseDuration(rest.String())
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %q", orig)
+		}
+		if err = add(d); err != nil {
+			return 0, err
+		}
+	}
+	if neg {
+		dur = -dur
+	}
+	return dur, nil
+}
+
+// Unit：time.Duration参数值为不带单位的数字时，以unit为单位解析，如Unit(time.Second)时"30"等同于"30s"。
+// 带单位的参数值不受影响。对[]time.Duration等参数同样有效。
+func (f *Flag) Unit(unit time.Duration) *Flag {
+	f.fs.unsealed()
+	p := f.p
+	if p.rtyp != typDuration && (p.elem == nil || p.elem.rtyp != typDuration) {
+		panic(fmt.Errorf("flags: option %v: unit requires duration type, got %v", p.name(), p.typ))
+	}
+	if unit <= 0 {
+		panic(fmt.Errorf("flags: option %v: invalid unit %v", p.name(), unit))
+	}
+
+	p.unit = unit
+	if p.elem != nil {
+		p.elem.unit = unit
+	}
+	if p.set != nil {
+		p.set = func(ptr any, s string) error {
+			d, err := p.parseDuration(s)
+			if err != nil {
+				return err
+			}
+			*ptr.(*time.Duration) = d
+			return nil
+		}
+	}
+	f.fs.touch()
+	return f
+}
+
+// parseDuration：按ParseDuration解析，设置了Unit时支持不带单位的数字。
+func (p *param) parseDuration(s string) (time.Duration, error) {
+	if p.unit > 0 {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return time.Duration(f * float64(p.unit)), nil
+		}
+	}
+	return ParseDuration(s)
+}
@@ -0,0 +1,80 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		s   string
+		dur time.Duration
+	}{
+		{"0", 0},
+		{"1h30m", 90 * time.Minute},
+		{"1d", 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"-1w1d", -8 * 24 * time.Hour},
+		{"1w2d3h4m5s", (9*24+3)*time.Hour + 4*time.Minute + 5*time.Second},
+	}
+	for _, c := range cases {
+		dur, err := ParseDuration(c.s)
+		if err != nil {
+			t.Fatalf("parse duration %q: %v", c.s, err)
+		}
+		if dur != c.dur {
+			t.Fatalf("parse duration %q: %v", c.s, dur)
+		}
+	}
+
+	for _, s := range []string{"", "-", "1", "d", "1x", "1.2.3d"} {
+		if _, err := ParseDuration(s); err == nil {
+			t.Fatalf("parse duration %q: no err", s)
+		}
+	}
+
+	// out of range instead of wrapping around
+	for _, s := range []string{"200000w", "-200000w", "106752d", "15250w2d", "15250w2000000h"} {
+		if dur, err := ParseDuration(s); !errors.Is(err, strconv.ErrRange) {
+			t.Fatalf("parse duration %q: %v, %v", s, dur, err)
+		}
+	}
+	if dur, err := ParseDuration("15250w"); err != nil || dur != 15250*7*24*time.Hour {
+		t.Fatalf("parse duration 15250w: %v, %v", dur, err)
+	}
+}
+
+func TestDurationUnit(t *testing.T) {
+	fs := New("unit", "")
+	timeout := fs.Duration('t', "timeout", 0, "timeout")
+	retries := Slice[time.Duration](fs, 'r', "retries", nil, "retry backoff")
+	fs.Flag("timeout").Unit(time.Second)
+	fs.Flag("retries").Unit(time.Millisecond)
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "-t", "30", "-r", "100", "-r", "1.5", "-r", "2s")
+	if err != nil {
+		t.Fatalf("unit run: %v", err)
+	}
+	if *timeout != 30*time.Second {
+		t.Fatalf("unit result: %v", *timeout)
+	}
+	if !sliceEqual(*retries, 100*time.Millisecond, 1500*time.Microsecond, 2*time.Second) {
+		t.Fatalf("unit slice result: %v", *retries)
+	}
+
+	fs.Reset()
+	if _, err = fs.Run(context.Background(), "-t", "1d"); err != nil || *timeout != 24*time.Hour {
+		t.Fatalf("unit run with suffix: %v %v", err, *timeout)
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, "--timeout duration (unit: 1s)") {
+		t.Fatalf("unit usage: %v", usage)
+	}
+}
@@ -0,0 +1,81 @@
+package flags
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// SetLookupEnv：设置读取环境变量的函数，默认为os.LookupEnv，便于测试时注入环境变量，见Flag.Env。
+func (fs *FlagSet) SetLookupEnv(fn func(key string) (string, bool)) *FlagSet {
+	fs.unsealed()
+	fs.root().lookupEnv = fn
+	return fs
+}
+
+// LookupEnvFunc：SetLookupEnv设置的函数，未设置时为nil。
+func (fs *FlagSet) LookupEnvFunc() func(key string) (string, bool) {
+	return fs.root().lookupEnv
+}
+
+func (fs *FlagSet) getenv(key string) (string, bool) {
+	if fn := fs.root().lookupEnv; fn != nil {
+		return fn(key)
+	}
+	return os.LookupEnv(key)
+}
+
+// SetClock：设置获取当前时间的函数，默认为time.Now，便于测试时固定时间。
+func (fs *FlagSet) SetClock(now func() time.Time) *FlagSet {
+	fs.unsealed()
+	fs.root().clock = now
+	return fs
+}
+
+// ClockFunc：SetClock设置的函数，未设置时为nil。
+func (fs *FlagSet) ClockFunc() func() time.Time {
+	return fs.root().clock
+}
+
+// Now：当前时间，见SetClock。延迟计算的默认值中应使用fs.Now()代替time.Now()，如：
+//
+//	fs.AnyVar(&since, NoShort, "since", func() time.Time { return fs.Now().Add(-time.Hour) }, "start time")
+func (fs *FlagSet) Now() time.Time {
+	if now := fs.root().clock; now != nil {
+		return now()
+	}
+	return time.Now()
+}
+
+// ExpandEnv：字符串参数值（包括[]string、map[string]string等）在解析前展开其中的"${VAR}"及"$VAR"，
+// 如"--data-dir='${HOME}/data'"，"$$"表示"$"本身，未设置的环境变量展开为空。环境变量的读取见SetLookupEnv。
+// slice及map参数在拆分元素之前展开，展开结果的拆分方式与未开启ExpandEnv时相同。
+func (f *Flag) ExpandEnv() *Flag {
+	f.fs.unsealed()
+	leaf := f.p
+	for leaf.elem != nil {
+		leaf = leaf.elem
+	}
+	if leaf.kind != reflect.String {
+		panic(fmt.Errorf("flags: option %v: expand env requires string type, got %v", f.p.name(), f.p.typ))
+	}
+	f.p.expandEnv = true
+	f.fs.touch()
+	return f
+}
+
+// _expandEnv：展开下一个参数值中的环境变量。
+func (fs *FlagSet) _expandEnv(args *arguments) *arguments {
+	if args.end() {
+		return args
+	}
+	val := os.Expand(args.next(), func(key string) string {
+		if key == "$" {
+			return "$"
+		}
+		val, _ := fs.getenv(key)
+		return val
+	})
+	return &arguments{args: []string{val}, align: args.align}
+}
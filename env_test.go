@@ -0,0 +1,46 @@
+package flags
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"HOME": "/home/flags", "HOST": "example.com"}
+	fs := New("expand", "").SetLookupEnv(func(key string) (string, bool) {
+		val, ok := env[key]
+		return val, ok
+	})
+	dir := fs.Str('d', "data-dir", "", "data directory")
+	url := fs.Str('u', "url", "", "url")
+	tags := Slice[string](fs, 't', "tags", nil, "tags")
+	raw := fs.Str('r', "raw", "", "not expanded")
+	plain := Slice[string](fs, 'p', "plain", nil, "not expanded")
+	fs.Flag("data-dir").ExpandEnv().Env("APP_DATA_DIR")
+	fs.Flag("url").ExpandEnv()
+	fs.Flag("tags").ExpandEnv()
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "--url=https://${HOST}/$$price?u=$USER", "-t", "$HOST", "-r", "$HOME")
+	if err != nil {
+		t.Fatalf("expand run: %v", err)
+	}
+	if *url != "https://example.com/$price?u=" || !sliceEqual(*tags, "example.com") || *raw != "$HOME" {
+		t.Fatalf("expand result: %v %q %v", *url, *tags, *raw)
+	}
+
+	// splitting is the same as without ExpandEnv
+	env["LIST"] = "x,y"
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "-t", "a,b", "--tags=$LIST", "-p", "a,b", "--plain=x,y")
+	if err != nil || !sliceEqual(*tags, "a,b", "x", "y") || !sliceEqual(*plain, *tags...) {
+		t.Fatalf("expand split: %v %q %q", err, *tags, *plain)
+	}
+
+	// env sourced
+	env["APP_DATA_DIR"] = "${HOME}/data"
+	fs.Reset()
+	if _, err = fs.Run(context.Background()); err != nil || *dir != "/home/flags/data" {
+		t.Fatalf("expand env sourced: %v %v", err, *dir)
+	}
+}
@@ -0,0 +1,64 @@
+package flags
+
+// listeners：解析过程事件的监听函数，见FlagSet.OnFlagParsed等。
+type listeners struct {
+	flag     []func(cmd *FlagSet, option string, f *Flag)
+	command  []func(cmd *FlagSet)
+	defaults []func(cmd *FlagSet)
+}
+
+// OnFlagParsed：监听参数解析事件，每当命令行参数、位置参数或环境变量的值解析成功后调用，
+// cmd为参数所属命令，option为参数在命令行中的形式，如"--output"、"-o"，环境变量为"$APP_OUTPUT"。
+// 适用于审计、缓存、统计等无需逐个包装参数的横切逻辑。可多次调用注册多个监听函数。仅根命令有效。
+func (fs *FlagSet) OnFlagParsed(fn func(cmd *FlagSet, option string, f *Flag)) *FlagSet {
+	fs.unsealed()
+	root := fs.root()
+	root.events.flag = append(root.events.flag, fn)
+	return fs
+}
+
+// OnCommandResolved：监听命令解析完成事件，解析到最终执行的命令且全部参数解析成功后调用。
+// 开启ChainCommands时每条命令调用一次。可多次调用注册多个监听函数。仅根命令有效。
+func (fs *FlagSet) OnCommandResolved(fn func(cmd *FlagSet)) *FlagSet {
+	fs.unsealed()
+	root := fs.root()
+	root.events.command = append(root.events.command, fn)
+	return fs
+}
+
+// OnDefaultsApplied：监听默认值设置事件，命令行中未出现的参数按环境变量、默认值设置完成后调用。
+// 解析路径上的每一级命令各调用一次。可多次调用注册多个监听函数。仅根命令有效。
+func (fs *FlagSet) OnDefaultsApplied(fn func(cmd *FlagSet)) *FlagSet {
+	fs.unsealed()
+	root := fs.root()
+	root.events.defaults = append(root.events.defaults, fn)
+	return fs
+}
+
+// flagParsed：参数解析成功，输出Trace并通知OnFlagParsed监听函数。
+// map、slice元素等内部参数没有名称，不会触发。
+func (fs *FlagSet) flagParsed(option string, p *param) {
+	if p.long == "" && p.short == "" && p.arg == "" {
+		return
+	}
+	if fs.tracing() {
+		fs.tracef("set %v = %v (%v)", p.name(), p.traceValue(), option)
+	}
+	for _, fn := range fs.root().events.flag {
+		fn(fs, option, &Flag{fs: fs, p: p})
+	}
+}
+
+// commandResolved：通知OnCommandResolved监听函数。
+func (fs *FlagSet) commandResolved() {
+	for _, fn := range fs.root().events.command {
+		fn(fs)
+	}
+}
+
+// defaultsApplied：通知OnDefaultsApplied监听函数。
+func (fs *FlagSet) defaultsApplied() {
+	for _, fn := range fs.root().events.defaults {
+		fn(fs)
+	}
+}
@@ -0,0 +1,51 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestParseEvents(t *testing.T) {
+	fs := New("app", "")
+	fs.SetLookupEnv(func(key string) (string, bool) {
+		if key == "APP_LEVEL" {
+			return "debug", true
+		}
+		return "", false
+	})
+	fs.Str('c', "config", "app.cfg", "config file")
+	fs.Str(0, "level", "info", "log level")
+	fs.Flag("level").Env("APP_LEVEL")
+
+	var events []string
+	fs.OnFlagParsed(func(cmd *FlagSet, option string, f *Flag) {
+		events = append(events, fmt.Sprintf("flag %v %v=%v (%v)", cmd.fullName(), option, f.Value(), f.Source()))
+	})
+	fs.OnDefaultsApplied(func(cmd *FlagSet) {
+		events = append(events, "defaults "+cmd.fullName())
+	})
+	fs.OnCommandResolved(func(cmd *FlagSet) {
+		events = append(events, "resolved "+cmd.fullName())
+	})
+
+	build := fs.Cmd("build", "")
+	Rest[string](build, "targets", 0, "")
+	build.Handle(func(ctx context.Context) {})
+
+	_, err := fs.Run(context.Background(), "-c", "x.cfg", "build", "a")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	want := []string{
+		"flag app -c=x.cfg (flag)",
+		"flag app $APP_LEVEL=debug (env)",
+		"defaults app",
+		"flag app build targets=[a] (flag)",
+		"defaults app build",
+		"resolved app build",
+	}
+	if !sliceEqual(events, want...) {
+		t.Fatalf("events: %q", events)
+	}
+}
@@ -0,0 +1,51 @@
+package flags
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ExportEnv：开启内置参数--export-env。Run时如出现该参数，解析完成后将最终命令中绑定了环境变量（见Flag.Env）的参数值
+// 以shell的export语句打印到stdout，不执行命令，便于"解析一次，在脚本中source"，如：
+//
+//	eval "$(app deploy --env prod --export-env)"
+//
+// 开启后帮助信息中列出--export-env。仅根命令有效。
+func (fs *FlagSet) ExportEnv() *FlagSet {
+	fs.touch()
+	fs.root().exportEnv = true
+	return fs
+}
+
+// WriteExportEnv：将绑定了环境变量的参数值以export语句写入w，值为命令行中的字符串形式，
+// 重新读取环境变量时可得到相同的值。通过Flag.Secret标记的参数不会输出值，仅以注释形式列出。如：
+//
+//	# app deploy
+//	export APP_OUTPUT='out.txt'
+//	export APP_TAGS='a,b'
+//	# APP_TOKEN: secret, not exported
+func (fs *FlagSet) WriteExportEnv(w io.Writer) error {
+	b := new(bytes.Buffer)
+	fmt.Fprintf(b, "# %v\n", fs.fullName())
+	for _, p := range fs.allParams() {
+		if p.env == "" {
+			continue
+		}
+		if p.secret {
+			fmt.Fprintf(b, "# %v: secret, not exported\n", p.env)
+			continue
+		}
+		val := formatValue(p, reflect.ValueOf(p.ptr).Elem())
+		fmt.Fprintf(b, "export %v=%v\n", p.env, shellQuote(val))
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// shellQuote：以单引号转义，使s在POSIX shell中保持原样。
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
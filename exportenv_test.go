@@ -0,0 +1,52 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestExportEnv(t *testing.T) {
+	env := map[string]string{"APP_TAGS": `a\,b,c`}
+	fs := New("app", "").ExportEnv()
+	fs.SetLookupEnv(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+	deploy := fs.Cmd("deploy", "")
+	deploy.Str('o', "output", "out.txt", "")
+	deploy.Flag("output").Env("APP_OUTPUT")
+	Slice[string](deploy, 't', "tags", nil, "")
+	deploy.Flag("tags").Env("APP_TAGS")
+	deploy.Str(0, "token", "x", "")
+	deploy.Flag("token").Env("APP_TOKEN").Secret()
+	deploy.Int(0, "level", 1, "") // not bound to env
+	var ran bool
+	deploy.Handle(func(context.Context) { ran = true })
+
+	stdout := new(bytes.Buffer)
+	fs.SetStdio(nil, stdout, nil)
+	if _, err := fs.Run(context.Background(), "deploy", "-o", "it's.txt", "--export-env"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	exp := `# app deploy
+export APP_OUTPUT='it'\''s.txt'
+export APP_TAGS='a\,b,c'
+# APP_TOKEN: secret, not exported
+`
+	if ran || stdout.String() != exp {
+		t.Fatalf("ran: %v, export env:\n%s", ran, stdout)
+	}
+
+	// exported values read back from the environment give the same result
+	env["APP_OUTPUT"] = "it's.txt"
+	fs.Reset()
+	stdout.Reset()
+	if _, err := fs.Run(context.Background(), "deploy", "--export-env"); err != nil || stdout.String() != exp {
+		t.Fatalf("export env from env: %v\n%s", err, stdout)
+	}
+	if usage := deploy.Usage(); !strings.Contains(usage, "  --export-env\n") {
+		t.Fatalf("usage: %v", usage)
+	}
+}
@@ -0,0 +1,267 @@
+package flags
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// resource：执行Handler前打开、执行后关闭的参数，如OutputFile、Reader、Writer参数。
+type resource interface {
+	open(fs *FlagSet) error  // 按参数值打开资源，fs为执行的命令
+	close(failed bool) error // 关闭资源，failed表示Handler返回错误或panic
+}
+
+// openResources：打开命令参数绑定的资源，返回按打开顺序逆序关闭的函数。
+func (fs *FlagSet) openResources() (closeAll func(failed bool) error, err error) {
+	if fs.findParam(func(p *param) bool { return p.res != nil }) == nil {
+		return closeNone, nil
+	}
+
+	var opened []resource
+	closeAll = func(failed bool) error {
+		var errs []error
+		for i := len(opened) - 1; i >= 0; i-- {
+			errs = append(errs, opened[i].close(failed))
+		}
+		return errors.Join(errs...)
+	}
+	for _, p := range fs.allParams() {
+		if p.res == nil {
+			continue
+		}
+		if err = p.res.open(fs); err != nil {
+			closeAll(true)
+			return nil, fmt.Errorf("%v: option %v: %w", fs.fullName(), p.name(), err)
+		}
+		opened = append(opened, p.res)
+	}
+	return closeAll, nil
+}
+
+func closeNone(bool) error { return nil }
+
+// OpenMode：OutputFile参数打开文件的方式，可组合使用，如OpenCreate|OpenTruncate。
+type OpenMode int
+
+const (
+	OpenCreate    OpenMode = 1 << iota // 文件不存在时创建
+	OpenTruncate                       // 打开时清空文件
+	OpenAppend                         // 追加写入
+	OpenExclusive                      // 文件必须不存在，与OpenCreate同时生效
+	OpenAtomic                         // 写入同目录下的临时文件，Handler成功返回后重命名为目标文件，失败时删除
+)
+
+func (fs *FlagSet) OutputFile(short rune, long string, mode OpenMode, desc string) **os.File {
+	ptr := new(*os.File)
+	fs.OutputFileVar(ptr, short, long, mode, desc)
+	return ptr
+}
+
+// OutputFileVar：输出文件参数，参数值为文件名，在执行Handler前按mode打开，并通过ptr交给Handler，
+// Handler返回后自动关闭。参数值为空时不打开文件，*ptr为nil。打开失败时不执行Handler。
+func (fs *FlagSet) OutputFileVar(ptr **os.File, short rune, long string, mode OpenMode, desc string) {
+	if mode&OpenAtomic != 0 && mode&OpenAppend != 0 {
+		panic(fmt.Errorf("flags: output file %v: atomic cannot be used with append", long))
+	}
+	name := new(string)
+	p := addTyped(fs, name, short, long, "", desc, parseString)
+	p.typ = "file"
+	p.res = &outputFile{name: name, ptr: ptr, mode: mode}
+}
+
+type outputFile struct {
+	name *string
+	ptr  **os.File
+	mode OpenMode
+	tmp  string // OpenAtomic时的临时文件
+}
+
+func (o *outputFile) open(*FlagSet) error {
+	*o.ptr = nil
+	o.tmp = ""
+	name := *o.name
+	if name == "" {
+		return nil
+	}
+
+	if o.mode&OpenAtomic != 0 {
+		if o.mode&OpenExclusive != 0 {
+			if _, err := os.Lstat(name); err == nil {
+				return fmt.Errorf("open %v: %w", name, os.ErrExist)
+			}
+		}
+		if o.mode&OpenCreate == 0 {
+			if _, err := os.Stat(name); err != nil {
+				return err
+			}
+		}
+		f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+		if err != nil {
+			return err
+		}
+		o.tmp = f.Name()
+		*o.ptr = f
+		return nil
+	}
+
+	flag := os.O_WRONLY
+	if o.mode&OpenCreate != 0 {
+		flag |= os.O_CREATE
+	}
+	if o.mode&OpenTruncate != 0 {
+		flag |= os.O_TRUNC
+	}
+	if o.mode&OpenAppend != 0 {
+		flag |= os.O_APPEND
+	}
+	if o.mode&OpenExclusive != 0 {
+		flag |= os.O_CREATE | os.O_EXCL
+	}
+	f, err := os.OpenFile(name, flag, 0666)
+	if err != nil {
+		return err
+	}
+	*o.ptr = f
+	return nil
+}
+
+func (o *outputFile) close(failed bool) error {
+	f := *o.ptr
+	if f == nil {
+		return nil
+	}
+	err := f.Close()
+	if o.tmp == "" {
+		return err
+	}
+
+	if failed || err != nil {
+		os.Remove(o.tmp)
+		return err
+	}
+	perm := os.FileMode(0644)
+	if fi, err := os.Stat(*o.name); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	if err = os.Chmod(o.tmp, perm); err == nil {
+		err = os.Rename(o.tmp, *o.name)
+	}
+	if err != nil {
+		os.Remove(o.tmp)
+	}
+	return err
+}
+
+func (fs *FlagSet) Reader(short rune, long string, dft string, desc string) *io.Reader {
+	ptr := new(io.Reader)
+	fs.ReaderVar(ptr, short, long, dft, desc)
+	return ptr
+}
+
+// ReaderVar：输入源参数，参数值为"-"时为stdin，否则为对应文件，在执行Handler前打开并通过ptr交给Handler，
+// Handler返回后自动关闭。dft一般为"-"，即默认从stdin读取；参数值为空时*ptr为nil。
+func (fs *FlagSet) ReaderVar(ptr *io.Reader, short rune, long string, dft string, desc string) {
+	name := new(string)
+	p := addTyped(fs, name, short, long, dft, desc, parseString)
+	p.typ = "file"
+	p.res = &inputFile{name: name, ptr: ptr}
+}
+
+type inputFile struct {
+	name *string
+	ptr  *io.Reader
+	file *os.File // 打开的文件，stdin时为nil
+}
+
+func (in *inputFile) open(fs *FlagSet) error {
+	*in.ptr = nil
+	in.file = nil
+	switch *in.name {
+	case "":
+		return nil
+	case "-":
+		*in.ptr = fs.Stdin()
+		return nil
+	}
+
+	f, err := os.Open(*in.name)
+	if err != nil {
+		return err
+	}
+	in.file = f
+	*in.ptr = f
+	return nil
+}
+
+func (in *inputFile) close(bool) error {
+	if in.file == nil {
+		return nil
+	}
+	return in.file.Close()
+}
+
+func (fs *FlagSet) Writer(short rune, long string, dft string, desc string) *io.Writer {
+	ptr := new(io.Writer)
+	fs.WriterVar(ptr, short, long, dft, desc)
+	return ptr
+}
+
+// WriterVar：输出目标参数，参数值为"-"或"stdout"时为stdout，"stderr"时为stderr，否则创建（或清空）对应文件。
+// 在执行Handler前打开并通过ptr交给Handler，写入经过缓冲，Handler返回后自动刷新缓冲并关闭文件。
+// dft一般为"-"，即默认输出到stdout；参数值为空时*ptr为nil。
+func (fs *FlagSet) WriterVar(ptr *io.Writer, short rune, long string, dft string, desc string) {
+	name := new(string)
+	p := addTyped(fs, name, short, long, dft, desc, parseString)
+	p.typ = "file"
+	p.res = &outputWriter{name: name, ptr: ptr}
+}
+
+type outputWriter struct {
+	name *string
+	ptr  *io.Writer
+	buf  *bufio.Writer
+	file *os.File // 创建的文件，stdout、stderr时为nil
+}
+
+func (o *outputWriter) open(fs *FlagSet) error {
+	*o.ptr = nil
+	o.buf = nil
+	o.file = nil
+
+	var w io.Writer
+	switch *o.name {
+	case "":
+		return nil
+	case "-", "stdout":
+		w = fs.Stdout()
+	case "stderr":
+		w = fs.Stderr()
+	default:
+		f, err := os.Create(*o.name)
+		if err != nil {
+			return err
+		}
+		o.file = f
+		w = f
+	}
+	o.buf = bufio.NewWriter(w)
+	*o.ptr = o.buf
+	return nil
+}
+
+func (o *outputWriter) close(bool) error {
+	if o.buf == nil {
+		return nil
+	}
+	err := o.buf.Flush()
+	if o.file != nil {
+		if cerr := o.file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputFile(t *testing.T) {
+	tmp := t.TempDir()
+	fs := New("output", "")
+	out := fs.OutputFile('o', "output", OpenCreate|OpenTruncate, "output file")
+	log := fs.OutputFile('l', "log", OpenCreate|OpenAppend, "log file")
+	report := fs.OutputFile('r', "report", OpenCreate|OpenAtomic, "report file")
+	fs.OutputFile(NoShort, "once", OpenExclusive, "created once")
+
+	var fail bool
+	var handled *os.File
+	fs.HandleE(func(context.Context) error {
+		handled = *out
+		if *out != nil {
+			fmt.Fprint(*out, "out")
+		}
+		if *log != nil {
+			fmt.Fprint(*log, "log;")
+		}
+		if *report != nil {
+			fmt.Fprint(*report, "report")
+		}
+		if fail {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	read := func(name string) string {
+		b, _ := os.ReadFile(filepath.Join(tmp, name))
+		return string(b)
+	}
+	run := func(args ...string) error {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), args...)
+		return err
+	}
+
+	if err := run(); err != nil || handled != nil {
+		t.Fatalf("output run without file: %v %v", err, handled)
+	}
+
+	args := []string{"-o", filepath.Join(tmp, "out"), "-l", filepath.Join(tmp, "log"), "-r", filepath.Join(tmp, "report")}
+	for i := 0; i < 2; i++ {
+		if err := run(args...); err != nil {
+			t.Fatalf("output run: %v", err)
+		}
+	}
+	if read("out") != "out" || read("log") != "log;log;" || read("report") != "report" {
+		t.Fatalf("output result: %q %q %q", read("out"), read("log"), read("report"))
+	}
+	if _, err := handled.Write([]byte("x")); err == nil {
+		t.Fatalf("output file not closed")
+	}
+
+	// atomic: keep old content on failure
+	os.WriteFile(filepath.Join(tmp, "report"), []byte("old"), 0600)
+	fail = true
+	if err := run("-r", filepath.Join(tmp, "report")); err == nil {
+		t.Fatalf("output run: no err")
+	}
+	if read("report") != "old" {
+		t.Fatalf("atomic report on failure: %q", read("report"))
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 3 {
+		t.Fatalf("atomic temp file left: %v", entries)
+	}
+	fail = false
+
+	// exclusive
+	if err := run("--once", filepath.Join(tmp, "once")); err != nil {
+		t.Fatalf("exclusive run: %v", err)
+	}
+	if err := run("--once", filepath.Join(tmp, "once")); err == nil {
+		t.Fatalf("exclusive run: no err")
+	}
+	if err := run("-o", filepath.Join(tmp, "missing", "out")); err == nil {
+		t.Fatalf("output run missing dir: no err")
+	}
+}
+
+func TestReader(t *testing.T) {
+	tmp := t.TempDir()
+	file := filepath.Join(tmp, "input")
+	os.WriteFile(file, []byte("from file"), 0644)
+
+	stdin, err := os.CreateTemp(tmp, "stdin")
+	if err != nil {
+		t.Fatalf("create stdin: %v", err)
+	}
+	stdin.WriteString("from stdin")
+	stdin.Seek(0, io.SeekStart)
+	old := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = old }()
+
+	fs := New("reader", "")
+	input := fs.Reader('i', "input", "-", "input source")
+	var got string
+	var opened io.Reader
+	fs.Handle(func(context.Context) {
+		opened = *input
+		b, _ := io.ReadAll(*input)
+		got = string(b)
+	})
+
+	if _, err = fs.Run(context.Background()); err != nil || got != "from stdin" {
+		t.Fatalf("reader stdin: %v %q", err, got)
+	}
+
+	fs.Reset()
+	if _, err = fs.Run(context.Background(), "-i", file); err != nil || got != "from file" {
+		t.Fatalf("reader file: %v %q", err, got)
+	}
+	if _, err = opened.Read(make([]byte, 1)); err == nil || errors.Is(err, io.EOF) {
+		t.Fatalf("reader file not closed: %v", err)
+	}
+
+	fs.Reset()
+	if _, err = fs.Run(context.Background(), "-i", filepath.Join(tmp, "missing")); err == nil {
+		t.Fatalf("reader missing file: no err")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	tmp := t.TempDir()
+	stdout, err := os.CreateTemp(tmp, "stdout")
+	if err != nil {
+		t.Fatalf("create stdout: %v", err)
+	}
+	old := os.Stdout
+	os.Stdout = stdout
+	defer func() { os.Stdout = old }()
+
+	fs := New("writer", "")
+	output := fs.Writer('o', "output", "-", "output target")
+	fs.Handle(func(context.Context) {
+		fmt.Fprint(*output, "hello")
+	})
+
+	if _, err = fs.Run(context.Background()); err != nil {
+		t.Fatalf("writer stdout: %v", err)
+	}
+	if b, _ := os.ReadFile(stdout.Name()); string(b) != "hello" {
+		t.Fatalf("writer stdout result: %q", b)
+	}
+
+	file := filepath.Join(tmp, "out")
+	os.WriteFile(file, []byte("old content"), 0644)
+	fs.Reset()
+	if _, err = fs.Run(context.Background(), "-o", file); err != nil {
+		t.Fatalf("writer file: %v", err)
+	}
+	if b, _ := os.ReadFile(file); string(b) != "hello" {
+		t.Fatalf("writer file result: %q", b)
+	}
+
+	fs.Reset()
+	if _, err = fs.Run(context.Background(), "-o", filepath.Join(tmp, "missing", "out")); err == nil {
+		t.Fatalf("writer missing dir: no err")
+	}
+}
@@ -0,0 +1,63 @@
+package flags
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// FromFile：参数值可引用文件内容，值为"@/path/to/file"或"file:///path/to/file"时，以文件内容作为参数值，
+// 适用于证书、较长的token、查询语句等。文件内容末尾的一个换行符会被去掉。
+// 未以"@"或"file://"开头的值按原样解析。
+func (f *Flag) FromFile() *Flag {
+	f.fs.unsealed()
+	f.p.fromFile = true
+	f.fs.touch()
+	return f
+}
+
+// FromStdin：参数值为"-"时，从stdin读取全部内容作为参数值，末尾的一个换行符会被去掉，
+// 如"echo secret | app login --token -"。stdin只能读取一次，多个参数值都为"-"时，后读取的参数值为空。
+func (f *Flag) FromStdin() *Flag {
+	f.fs.unsealed()
+	f.p.fromStdin = true
+	f.fs.touch()
+	return f
+}
+
+// _readValueRef：如下一个参数值为文件引用或"-"，读取文件或stdin内容作为参数值。
+func (fs *FlagSet) _readValueRef(args *arguments, arg string, p *param) (*arguments, error) {
+	if args.end() {
+		return args, nil
+	}
+	val := args.peek()
+
+	var r io.Reader
+	switch {
+	case p.fromStdin && val == "-":
+		r = fs.Stdin()
+	case p.fromFile && strings.HasPrefix(val, "@"):
+		val = strings.TrimPrefix(val, "@")
+	case p.fromFile && strings.HasPrefix(val, "file://"):
+		val = strings.TrimPrefix(val, "file://")
+	default:
+		return args, nil
+	}
+	if r == nil {
+		f, err := os.Open(val)
+		if err != nil {
+			return args, fs._parseParamErr(arg, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return args, fs._parseParamErr(arg, err)
+	}
+	args.next()
+	content := strings.TrimSuffix(string(b), "\n")
+	content = strings.TrimSuffix(content, "\r")
+	return newArg(content), nil
+}
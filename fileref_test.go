@@ -0,0 +1,68 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFromFile(t *testing.T) {
+	dir := t.TempDir()
+	token := filepath.Join(dir, "token")
+	if err := os.WriteFile(token, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatalf("write token: %v", err)
+	}
+	tags := filepath.Join(dir, "tags")
+	if err := os.WriteFile(tags, []byte("a,b"), 0o600); err != nil {
+		t.Fatalf("write tags: %v", err)
+	}
+
+	fs := New("file", "")
+	tok := fs.Str(NoShort, "token", "", "")
+	ts := Slice[string](fs, NoShort, "tags", nil, "", ",")
+	user := fs.Str(NoShort, "user", "", "")
+	fs.Flag("token").FromFile()
+	fs.Flag("tags").FromFile()
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "--token", "@"+token, "--tags=file://"+tags, "--user", "@admin")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *tok != "s3cr3t" || !sliceEqual(*ts, "a", "b") || *user != "@admin" {
+		t.Fatalf("token: %q, tags: %q, user: %q", *tok, *ts, *user)
+	}
+
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "--token", "@"+filepath.Join(dir, "missing"))
+	if err == nil || !strings.Contains(err.Error(), "parse option --token") {
+		t.Fatalf("missing file: %v", err)
+	}
+}
+
+func TestFromStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	w.WriteString("s3cr3t\n")
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	fs := New("stdin", "")
+	tok := fs.Str(NoShort, "token", "", "")
+	user := fs.Str(NoShort, "user", "", "")
+	fs.Flag("token").FromStdin()
+	fs.Handle(func(context.Context) {})
+
+	if _, err = fs.Run(context.Background(), "--token", "-", "--user=-"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *tok != "s3cr3t" || *user != "-" {
+		t.Fatalf("token: %q, user: %q", *tok, *user)
+	}
+}
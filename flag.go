@@ -0,0 +1,204 @@
+package flags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Flag：已注册参数的句柄，用于设置参数的附加选项，如：
+//
+//	fs.Flag("data-dir").DefaultFrom(func() string { return filepath.Join(*home, "data") }, "home")
+type Flag struct {
+	fs *FlagSet
+	p  *param
+}
+
+// Flag：按长参数名或短参数名查找已注册的参数，name可带"-"或"--"前缀。未找到时panic。
+func (fs *FlagSet) Flag(name string) *Flag {
+	p := fs.lookup(name)
+	if p == nil {
+		panic(fmt.Errorf("flags: unknown option: %v", name))
+	}
+	return &Flag{fs: fs, p: p}
+}
+
+func (fs *FlagSet) lookup(name string) *param {
+	name = strings.TrimLeft(name, "-")
+	if name == "" {
+		return nil
+	}
+	long := fs.normalizeName(name)
+	if p := fs.findParam(func(p *param) bool { return p.long == long }); p != nil {
+		return p
+	}
+	return fs.findParam(func(p *param) bool { return p.short == name })
+}
+
+// DefaultFrom：参数默认值由其它参数解析后的值计算得到，如--data-dir默认为<--home>/data。
+// fn类型必须为func() T，T为参数变量类型；deps为fn依赖的参数名称。
+// 解析完成后按依赖顺序计算默认值，存在循环依赖时解析报错。
+func (f *Flag) DefaultFrom(fn any, deps ...string) *Flag {
+	f.fs.unsealed()
+	t1 := f.p.rtyp
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func {
+		panic(fmt.Errorf("flags: default func type %T must be func() %v", fn, t1))
+	}
+	if t2 := fv.Type(); t2.NumIn() != 0 || t2.NumOut() != 1 || t2.Out(0) != t1 {
+		panic(fmt.Errorf("flags: default func type %v must be func() %v", t2, t1))
+	}
+
+	f.p.deps = f.p.deps[:0]
+	for _, name := range deps {
+		dep := f.fs.lookup(name)
+		if dep == nil {
+			panic(fmt.Errorf("flags: unknown option: %v", name))
+		}
+		f.p.deps = append(f.p.deps, dep)
+	}
+	f.p.dft = nil
+	f.p.setDft = nil
+	f.p.dftFn = fv
+	f.fs.touch()
+	return f
+}
+
+// NoOptDefault：参数未带值时使用value作为参数值，类似pflag的NoOptDefVal。
+// 如设置--cache的NoOptDefault为"5m"，则"--cache"等同于"--cache=5m"，"--cache 1h"仍然有效。
+// 后续参数以"-"开头或为子命令时视为未带值，此时负数等值需使用"--opt=value"形式。
+func (f *Flag) NoOptDefault(value string) *Flag {
+	f.fs.unsealed()
+	f.p.noOpt = &value
+	f.fs.touch()
+	return f
+}
+
+// Negatable：为bool参数增加"--no-xx"形式，用于将默认值为true的参数置为false，Usage中显示为"--[no-]xx"。
+func (f *Flag) Negatable() *Flag {
+	f.fs.unsealed()
+	if f.p.kind != reflect.Bool {
+		panic(fmt.Errorf("flags: negatable option %v must be bool", f.p.name()))
+	}
+	if f.p.long == "" {
+		panic(fmt.Errorf("flags: negatable option -%v must have a long name", f.p.short))
+	}
+	if f.fs.lookup("no-"+f.p.long) != nil {
+		panic(fmt.Errorf("flags: duplicated long option: --no-%v", f.p.long))
+	}
+	f.p.negatable = true
+	f.fs.touch()
+	return f
+}
+
+// negated：查找"--no-xx"对应的bool参数。
+func (fs *FlagSet) negated(arg string) *param {
+	long := strings.TrimPrefix(arg, "--no-")
+	if long == arg {
+		return nil
+	}
+	if p := fs.index().long[long]; p != nil && p.negatable {
+		return p
+	}
+	return nil
+}
+
+// SliceMode：slice参数重复出现时的赋值方式。
+// 无论哪种方式，"--xx="（值为空）都会清空slice。
+type SliceMode int
+
+const (
+	SliceAppend  SliceMode = iota // 每次出现都追加到slice末尾，默认方式
+	SliceReplace                  // 每次出现都替换之前的值，以最后一次出现为准
+)
+
+func (m SliceMode) String() string {
+	switch m {
+	case SliceAppend:
+		return "append"
+	case SliceReplace:
+		return "replace"
+	default:
+		return fmt.Sprintf("SliceMode(%d)", int(m))
+	}
+}
+
+// SliceMode：设置slice参数重复出现时的赋值方式，Usage中会显示所用方式。
+func (f *Flag) SliceMode(mode SliceMode) *Flag {
+	f.fs.unsealed()
+	if !f.p.isSlice() {
+		panic(fmt.Errorf("flags: slice mode option %v must be slice", f.p.name()))
+	}
+	f.p.mode = mode
+	f.fs.touch()
+	return f
+}
+
+// DupPolicy：map参数出现重复key时的处理方式。
+type DupPolicy int
+
+const (
+	DupOverwrite DupPolicy = iota // 后出现的覆盖先出现的，默认方式
+	DupKeepFirst                  // 保留先出现的，忽略后出现的
+	DupError                      // 报错
+)
+
+// MapDuplicate：设置map参数出现重复key时的处理方式，包括多次出现该参数时的重复key。
+// value为slice的map，重复key的值总是追加，不受此设置影响。
+func (f *Flag) MapDuplicate(policy DupPolicy) *Flag {
+	f.fs.unsealed()
+	if f.p.kind != reflect.Map || f.p.elem.isSlice() {
+		panic(fmt.Errorf("flags: map duplicate option %v must be map with non-slice value", f.p.name()))
+	}
+	f.p.dup = policy
+	f.fs.touch()
+	return f
+}
+
+// Greedy：slice参数连续消耗后续参数值，直到遇到下一个参数（以"-"开头）或子命令，
+// 如"--files a.txt b.txt c.txt"，Usage中类型后显示"..."。
+func (f *Flag) Greedy() *Flag {
+	f.fs.unsealed()
+	if !f.p.isSlice() {
+		panic(fmt.Errorf("flags: greedy option %v must be slice", f.p.name()))
+	}
+	f.p.greedy = true
+	f.fs.touch()
+	return f
+}
+
+// Env：绑定环境变量，命令行未设置该参数时，以环境变量的值为准（格式同"--xx=value"），都未设置时使用默认值。
+// Usage中会显示绑定的环境变量，如"--output string (env: APP_OUTPUT)"。
+func (f *Flag) Env(name string) *Flag {
+	f.fs.unsealed()
+	f.p.env = name
+	f.fs.touch()
+	return f
+}
+
+// Secret：标记为敏感参数，如密码、token等，--print-config输出时以"******"代替参数值。
+func (f *Flag) Secret() *Flag {
+	f.fs.unsealed()
+	f.p.secret = true
+	f.fs.touch()
+	return f
+}
+
+// Metavar：设置Usage中参数值的占位名称，代替由变量类型生成的名称，如"--output FILE"代替"--output string"，
+// 适用于map[string][]time.Duration等类型名称难以阅读的参数。
+func (f *Flag) Metavar(name string) *Flag {
+	f.fs.unsealed()
+	f.p.meta = name
+	f.fs.touch()
+	return f
+}
+
+// Source：参数值来源，需在解析完成后调用。
+func (f *Flag) Source() Source {
+	return f.p.source
+}
+
+// Value：参数当前值。
+func (f *Flag) Value() any {
+	return reflect.ValueOf(f.p.ptr).Elem().Interface()
+}
@@ -0,0 +1,320 @@
+package flags
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultFrom(t *testing.T) {
+	fs := New("default_from", "")
+	dataDir := fs.Str('d', "data-dir", "", "data directory")
+	home := fs.Str(NoShort, "home", "/home/flags", "home directory")
+	fs.Flag("data-dir").DefaultFrom(func() string { return filepath.Join(*home, "data") }, "home")
+	fs.Handle(func(context.Context) {})
+
+	// default
+	_, err := fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("default from run: %v", err)
+	}
+	if *dataDir != "/home/flags/data" {
+		t.Fatalf("default from result: %v", *dataDir)
+	}
+
+	// derived from parsed
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "--home", "/root")
+	if err != nil {
+		t.Fatalf("default from run: %v", err)
+	}
+	if *dataDir != "/root/data" {
+		t.Fatalf("default from result: %v", *dataDir)
+	}
+
+	// set
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "--home", "/root", "-d", "/data")
+	if err != nil {
+		t.Fatalf("default from run: %v", err)
+	}
+	if *dataDir != "/data" {
+		t.Fatalf("default from result: %v", *dataDir)
+	}
+
+	// cycle
+	fs.Flag("home").DefaultFrom(func() string { return *dataDir }, "data-dir")
+	fs.Reset()
+	_, err = fs.Run(context.Background())
+	if err == nil {
+		t.Fatalf("default from cycle: no err")
+	}
+}
+
+func TestNoOptDefault(t *testing.T) {
+	fs := New("no_opt", "")
+	cache := fs.Duration('c', "cache", 0, "cache duration")
+	fs.Bool('v', "verbose", false, "verbose")
+	fs.Flag("cache").NoOptDefault("5m")
+	fs.Cmd("sub", "").Handle(func(context.Context) {})
+	fs.Handle(func(context.Context) {})
+
+	cases := []struct {
+		args  []string
+		cache time.Duration
+	}{
+		{nil, 0},
+		{[]string{"--cache"}, 5 * time.Minute},
+		{[]string{"-c", "-v"}, 5 * time.Minute},
+		{[]string{"--cache", "1h"}, time.Hour},
+		{[]string{"--cache=2h"}, 2 * time.Hour},
+		{[]string{"--cache", "sub"}, 5 * time.Minute},
+	}
+	for _, c := range cases {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), c.args...)
+		if err != nil {
+			t.Fatalf("no opt run %q: %v", c.args, err)
+		}
+		if *cache != c.cache {
+			t.Fatalf("no opt run %q result: %v", c.args, *cache)
+		}
+	}
+	if usage := fs.Usage(); !strings.Contains(usage, `--cache duration[="5m"]`) {
+		t.Fatalf("no opt usage: %v", usage)
+	}
+}
+
+func TestNegatable(t *testing.T) {
+	fs := New("negatable", "")
+	color := fs.Bool('c', "color", true, "colorful output")
+	fs.Flag("color").Negatable()
+	fs.Handle(func(context.Context) {})
+
+	cases := []struct {
+		args  []string
+		color bool
+	}{
+		{nil, true},
+		{[]string{"--no-color"}, false},
+		{[]string{"--no-color", "--color"}, true},
+		{[]string{"-c"}, true},
+	}
+	for _, c := range cases {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), c.args...)
+		if err != nil {
+			t.Fatalf("negatable run %q: %v", c.args, err)
+		}
+		if *color != c.color {
+			t.Fatalf("negatable run %q result: %v", c.args, *color)
+		}
+	}
+
+	if _, err := fs.Run(context.Background(), "--no-colors"); err == nil {
+		t.Fatalf("negatable run unknown: no err")
+	}
+	if usage := fs.Usage(); !strings.Contains(usage, "-c, --[no-]color bool") {
+		t.Fatalf("negatable usage: %v", usage)
+	}
+}
+
+func TestSliceMode(t *testing.T) {
+	fs := New("slice_mode", "")
+	tags := Slice[string](fs, 't', "tags", []string{"a"}, "tags")
+	ids := Slice[int](fs, 'i', "ids", nil, "ids")
+	fs.Flag("ids").SliceMode(SliceReplace)
+	fs.Handle(func(context.Context) {})
+
+	cases := []struct {
+		args []string
+		tags []string
+		ids  []int
+	}{
+		{nil, []string{"a"}, nil},
+		{[]string{"-t", "x", "--tags=y,z"}, []string{"x", "y", "z"}, nil},
+		{[]string{"-t", "x", "--tags="}, nil, nil},
+		{[]string{"--tags=", "-t", "x"}, []string{"x"}, nil},
+		{[]string{"-i", "1", "--ids=2,3"}, []string{"a"}, []int{2, 3}},
+		{[]string{"--ids=2,3", "-i", "4"}, []string{"a"}, []int{4}},
+	}
+	for _, c := range cases {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), c.args...)
+		if err != nil {
+			t.Fatalf("slice mode run %q: %v", c.args, err)
+		}
+		if !sliceEqual(*tags, c.tags...) || !sliceEqual(*ids, c.ids...) {
+			t.Fatalf("slice mode run %q result: %q %v", c.args, *tags, *ids)
+		}
+	}
+
+	usage := fs.Usage()
+	if !strings.Contains(usage, "(mode: append)") || !strings.Contains(usage, "(mode: replace)") {
+		t.Fatalf("slice mode usage: %v", usage)
+	}
+}
+
+func TestMapDuplicate(t *testing.T) {
+	fs := New("map_dup", "")
+	overwrite := Map[string, int](fs, 'o', "overwrite", nil, "overwrite")
+	keep := Map[string, int](fs, 'k', "keep", nil, "keep first")
+	Map[string, int](fs, 'e', "error", nil, "error")
+	fs.Flag("keep").MapDuplicate(DupKeepFirst)
+	fs.Flag("error").MapDuplicate(DupError)
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "-o", "a:1,a:2", "-o", "a:3", "-k", "a:1,a:2", "-k", "a:3")
+	if err != nil {
+		t.Fatalf("map dup run: %v", err)
+	}
+	if (*overwrite)["a"] != 3 || (*keep)["a"] != 1 {
+		t.Fatalf("map dup result: %v %v", *overwrite, *keep)
+	}
+
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "-e", "a:1", "-e", "b:2,a:3")
+	if err == nil || !strings.Contains(err.Error(), "duplicated key: a") {
+		t.Fatalf("map dup error: %v", err)
+	}
+}
+
+func TestGreedy(t *testing.T) {
+	fs := New("greedy", "")
+	files := Slice[string](fs, 'f', "files", nil, "files")
+	verbose := fs.Bool('v', "verbose", false, "verbose")
+	fs.Flag("files").Greedy()
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "--files", "a.txt", "b.txt", "-v", "-f", "c.txt")
+	if err != nil {
+		t.Fatalf("greedy run: %v", err)
+	}
+	if !sliceEqual(*files, "a.txt", "b.txt", "c.txt") || !*verbose {
+		t.Fatalf("greedy result: %q %v", *files, *verbose)
+	}
+
+	fs.Flag("files").SliceMode(SliceReplace)
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "-f", "a.txt", "b.txt", "-f", "c.txt", "d.txt")
+	if err != nil {
+		t.Fatalf("greedy run: %v", err)
+	}
+	if !sliceEqual(*files, "c.txt", "d.txt") {
+		t.Fatalf("greedy replace result: %q", *files)
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, "--files []string...") {
+		t.Fatalf("greedy usage: %v", usage)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	fs := New("env", "")
+	output := fs.Str('o', "output", "stdout", "output file")
+	tags := Slice[string](fs, 't', "tags", nil, "tags")
+	fs.Flag("output").Env("FLAGS_TEST_OUTPUT")
+	fs.Flag("tags").Env("FLAGS_TEST_TAGS")
+	fs.Handle(func(context.Context) {})
+
+	// default
+	_, err := fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("env run: %v", err)
+	}
+	if *output != "stdout" || len(*tags) != 0 {
+		t.Fatalf("env run result: %v %q", *output, *tags)
+	}
+
+	// env
+	t.Setenv("FLAGS_TEST_OUTPUT", "env.txt")
+	t.Setenv("FLAGS_TEST_TAGS", "a,b")
+	fs.Reset()
+	_, err = fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("env run: %v", err)
+	}
+	if *output != "env.txt" || !sliceEqual(*tags, "a", "b") {
+		t.Fatalf("env run result: %v %q", *output, *tags)
+	}
+
+	// command line first
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "-o", "cli.txt")
+	if err != nil {
+		t.Fatalf("env run: %v", err)
+	}
+	if *output != "cli.txt" {
+		t.Fatalf("env run result: %v", *output)
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, `--output string (default: "stdout") (env: FLAGS_TEST_OUTPUT)`) {
+		t.Fatalf("env usage: %v", usage)
+	}
+}
+
+func TestInheritedSliceSources(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.json")
+	writeTestFile(t, config, `{}`)
+	fs := New("app", "").ConfigFiles(config).RememberLast(filepath.Join(dir, "last.json"))
+	tags := Slice[string](fs, 't', "tags", []string{"x"}, "")
+	labels := Map[string, string](fs, 'l', "labels", nil, "")
+	var env map[string]string
+	fs.SetLookupEnv(func(key string) (string, bool) {
+		val, ok := env[key]
+		return val, ok
+	})
+	fs.Flag("tags").Env("APP_TAGS")
+	fs.Flag("labels").Env("APP_LABELS")
+	fs.Handle(func(context.Context) {})
+	sub := fs.Cmd("sub", "")
+	sub.Handle(func(context.Context) {})
+
+	run := func(args ...string) {
+		t.Helper()
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), args...); err != nil {
+			t.Fatalf("run %q: %v", args, err)
+		}
+	}
+	// values resolved by the parent before the subcommand are replaced, not appended to
+	check := func(name string) {
+		t.Helper()
+		for _, args := range [][]string{{"--last", "-t", "c", "-l", "k:v"}, {"--last", "sub", "-t", "c", "-l", "k:v"}} {
+			run(args...)
+			if !sliceEqual(*tags, "c") || len(*labels) != 1 || (*labels)["k"] != "v" {
+				t.Fatalf("%v %q: tags: %q, labels: %v", name, args, *tags, *labels)
+			}
+		}
+	}
+
+	check("default")
+
+	env = map[string]string{"APP_TAGS": "a,b", "APP_LABELS": "a:1"}
+	check("env")
+	env = nil
+
+	writeTestFile(t, config, `{"tags": ["a", "b"], "labels": {"a": "1"}}`)
+	check("config")
+	writeTestFile(t, config, `{}`)
+
+	run("sub", "-t", "a", "-t", "b", "-l", "a:1")
+	check("last")
+}
+
+func TestMetavar(t *testing.T) {
+	fs := New("metavar", "")
+	fs.Str('o', "output", "", "output file")
+	Map[string, time.Duration](fs, NoShort, "retries", nil, "retry backoff")
+	fs.Flag("output").Metavar("FILE")
+	fs.Flag("retries").Metavar("NAME:DUR")
+	fs.Handle(func(context.Context) {})
+
+	usage := fs.Usage()
+	if !strings.Contains(usage, "-o, --output FILE\n") || !strings.Contains(usage, "--retries NAME:DUR\n") {
+		t.Fatalf("metavar usage: %v", usage)
+	}
+}
@@ -3,19 +3,28 @@ package flags
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // 时间参数格式
 const DateTime = "2006-01-02T15:04:05"
 
 const (
-	NoShort byte   = 0  // 不设置短参数
+	NoShort rune   = 0  // 不设置短参数
 	NoLong  string = "" // 不设置长参数
 )
 
@@ -23,32 +32,147 @@ var (
 	ErrNoExecFunc   = errors.New("no exec func")
 	ErrNoInputValue = errors.New("no input value")
 	ErrHelp         = errors.New("help")
+
+	ErrUnknownOption  = errors.New("unknown option")      // 无法识别的参数，见FlagSet.OnUnknownFlag
+	ErrUnknownCommand = errors.New("unknown sub command") // 无法识别的子命令，见FlagSet.OnUnknownCommand
 )
 
-// FlagSet提供一组参数解析/命令执行的绑定关系。不可复用，如需要重复解析，需重新生成新的FlagSet。
+// FlagSet提供一组参数解析/命令执行的绑定关系。如需要重复解析，需先调用Reset重置参数。
 type FlagSet struct {
-	name   string       // 命令名称
-	desc   string       // 命令描述
-	params []*param     // 命令参数
-	cmds   []*FlagSet   // 子命令
-	fn     Handler      // 命令执行代码
-	mws    []Middleware // 中间件
-	parent *FlagSet     // 父命令
-	stmt   *FlagSet
+	name    string        // 命令名称
+	desc    string        // 命令描述
+	params  []*param      // 命令参数，不含继承自父命令的参数，见FlagSet.eachParam
+	inherit int           // 继承父命令参数的个数
+	heirs   []*FlagSet    // 继承当前命令参数的子命令
+	idx     *paramIndex   // 参数索引，见FlagSet.index
+	posArgs []*param      // 位置参数，见FlagSet.ArgVar
+	rest    *param        // 可变位置参数，见FlagSet.RestVar
+	restMin int           // 可变位置参数最少个数
+	argsV   ArgsValidator // 位置参数个数校验，见FlagSet.ValidateArgs
+	cmds    []*FlagSet    // 子命令
+	fn      Handler       // 命令执行代码
+	mws     []Middleware  // 中间件
+	parent  *FlagSet      // 父命令
+	stmt    *FlagSet
+	stmts   []*FlagSet // 在当前命令上创建的语句，见FlagSet.Stmt
+	origin  *FlagSet   // 克隆来源，见FlagSet.Clone
+
+	aliases    map[string][]string  // 用户别名
+	deprecated *string              // 弃用提示，见FlagSet.Deprecate
+	locales    map[string]localized // 各语言下的名称及描述，见FlagSet.Localize
+
+	cmdIdx   map[string]*FlagSet // 子命令名称索引
+	localIdx map[string]*FlagSet // 当前语言下子命令名称及别名的索引，见FlagSet.subcmd
+	localFor string              // localIdx对应的语言
+
+	usage    string // 缓存的帮助信息，见FlagSet.Usage
+	usageVer uint64 // usage对应的注册版本
+
+	onUnknownFlag UnknownFlagHandler    // 无法识别的参数的处理函数，见FlagSet.OnUnknownFlag
+	onUnknownCmd  UnknownCommandHandler // 无法识别的子命令的处理函数，见FlagSet.OnUnknownCommand
+
+	plugin     string   // 外部插件路径，仅由PathPlugins生成的子命令有
+	pluginArgs []string // 传给外部插件的参数
+
+	nopager      bool // 关闭帮助信息分页，仅根命令有效
+	pathPlugins  bool // 开启外部插件子命令，仅根命令有效
+	printConfig  bool // 开启--print-config，仅根命令有效
+	printChanged bool // 开启--print-changed，仅根命令有效
+	rememberLast bool // 记录最近一次成功执行的参数并开启--last，仅根命令有效，见FlagSet.RememberLast
+	exportEnv    bool // 开启--export-env，仅根命令有效，见FlagSet.ExportEnv
+	strictDup    bool // 标量参数重复出现时报错，仅根命令有效
+	strictConfig bool // 配置文件中出现未知配置项时报错，仅根命令有效，见FlagSet.StrictConfig
+	singleDash   bool // 长参数可以"-"开头，仅根命令有效
+	abbrev       bool // 长参数可使用无歧义的前缀缩写，仅根命令有效
+	autoAlias    bool // 长参数中"-"和"_"等价，仅根命令有效
+	plainUsage   bool // 帮助信息使用纯文本格式，仅根命令有效，见FlagSet.PlainUsage
+	sealed       bool // 禁止继续注册，仅根命令有效，见FlagSet.Seal
+
+	limits Limits // 解析限制，仅根命令有效，见FlagSet.SetLimits
+
+	normalize func(name string) string // 长参数名称规范化函数，仅根命令有效
+	exitCodes []exitCode               // 错误到退出码的映射，仅根命令有效，见FlagSet.ExitCode
+	trace     io.Writer                // 解析过程输出，仅根命令有效，见FlagSet.Trace
+	dryRun    io.Writer                // 只解析不执行，解析结果输出，仅根命令有效，见FlagSet.DryRun
+
+	lookupEnv func(string) (string, bool) // 读取环境变量，仅根命令有效，见FlagSet.SetLookupEnv
+	clock     func() time.Time            // 当前时间，仅根命令有效，见FlagSet.SetClock
+	chainSep  string                      // 命令串联分隔符，仅根命令有效，见FlagSet.ChainCommands
+	onWarning func(Warning)               // 警告回调，仅根命令有效，见FlagSet.OnWarning
+	overflow  OverflowPolicy              // 整数参数溢出时的处理方式，仅根命令有效，见FlagSet.SetOverflow
+	exiter    Exiter                      // 退出进程的方式，仅根命令有效，见FlagSet.SetExiter
+	locale    string                      // 使用的语言，仅根命令有效，见FlagSet.SetLocale
+	warnings  []Warning                   // 最近一次解析产生的警告，仅根命令有效
+
+	configPaths []string               // 配置文件路径，仅根命令有效，见FlagSet.ConfigFiles
+	xdgApp      string                 // 从标准位置查找配置文件时的应用名称，仅根命令有效，见FlagSet.XDGConfig
+	configVals  map[string]configEntry // 最近一次解析时读取的配置项，仅根命令有效
+	configStamp []fileStamp            // 读取configVals前各配置文件的状态，仅根命令有效，见FlagSet.WatchConfig
+	lastPath    string                 // 记录最近一次执行参数的状态文件，仅根命令有效，见FlagSet.RememberLast
+
+	stdin  io.Reader // 标准输入，仅根命令有效，见FlagSet.SetStdio
+	stdout io.Writer // 标准输出，仅根命令有效
+	stderr io.Writer // 标准错误输出，仅根命令有效
+
+	preprocs []func([]string) ([]string, error) // 参数预处理函数，仅根命令有效，见FlagSet.PreprocessArgs
+	events   listeners                          // 解析过程事件监听，仅根命令有效，见FlagSet.OnFlagParsed
+
+	version uint64 // 注册版本，注册参数、命令或修改其属性时递增，用于使缓存失效，仅根命令有效
 }
 
 // param参数解析
 type param struct {
-	ptr    any    // 指针，解析到对应变量
-	typ    string // 参数类型，用于生成usage
-	dft    any    // 默认值，如果没有解析到ptr，则将ptr内容设置为dft
-	short  string // 短参数
-	long   string // 长参数
-	desc   string // 参数描述
-	parsed bool   // 是否已解析，用于判断是否将ptr设置为dft
+	ptr    any           // 指针，解析到对应变量
+	typ    string        // 参数类型，用于生成usage
+	dft    any           // 默认值，如果没有解析到ptr，则将ptr内容设置为dft
+	dftFn  reflect.Value // 延迟计算的默认值函数func() T，仅在没有解析到ptr时调用
+	short  string        // 短参数
+	long   string        // 长参数
+	desc   string        // 参数描述
+	parsed bool          // 是否已解析，用于判断是否将ptr设置为dft
 
 	sep1 string // seperator of every elem, used by slice & map
 	sep2 string // seperator of key/value, used by map
+	sep3 string // seperator of outer/inner key, used by nested map
+
+	rtyp    reflect.Type // 变量类型，即reflect.TypeOf(ptr).Elem()
+	kind    reflect.Kind // 变量类型的Kind
+	elem    *param       // slice元素/map value的解析参数
+	key     *param       // map key的解析参数
+	elemPtr bool         // slice元素是否为指针
+
+	set    func(ptr any, s string) error // 非反射赋值函数，仅类型化注册的标量参数有，ptr即param.ptr
+	setDft func(ptr any)                 // 非反射设置默认值函数，仅类型化注册的标量参数有
+
+	deps  []*param // 默认值依赖的参数，见Flag.DefaultFrom
+	noOpt *string  // 参数未带值时使用的值，见Flag.NoOptDefault
+
+	negatable bool      // bool参数是否支持--no-xx形式，见Flag.Negatable
+	mode      SliceMode // slice参数重复出现时的赋值方式，见Flag.SliceMode
+	dup       DupPolicy // map参数key重复时的处理方式，见Flag.MapDuplicate
+	greedy    bool      // slice参数是否连续消耗后续参数值，见Flag.Greedy
+
+	env    string        // 绑定的环境变量，见Flag.Env
+	secret bool          // 是否为敏感参数，见Flag.Secret
+	meta   string        // usage中参数值的占位名称，见Flag.Metavar
+	unit   time.Duration // 不带单位的时长参数值的单位，见Flag.Unit
+	scaled bool          // 整数参数值是否支持数量级后缀，见Flag.Scaled
+	numFmt *numberFormat // 数字参数值的千位分隔符及小数点，见Flag.NumberFormat
+
+	overflow *OverflowPolicy // 整数参数溢出时的处理方式，为nil时使用FlagSet.SetOverflow的设置
+	source   Source          // 参数值来源
+
+	fromFile  bool // 参数值可引用文件内容，见Flag.FromFile
+	fromStdin bool // 参数值为"-"时从stdin读取，见Flag.FromStdin
+	expandEnv bool // 参数值是否展开环境变量，见Flag.ExpandEnv
+
+	deprecated *string // 弃用提示，见Flag.Deprecated
+
+	path *pathCheck // 路径参数的检查项，仅Path、Dir参数有，见FlagSet.PathVar
+	res  resource   // 执行Handler前后打开、关闭的资源，见FlagSet.OutputFileVar等
+
+	arg   string   // 位置参数名称，见FlagSet.ArgVar
+	owner *FlagSet // 注册参数的命令，子命令可覆盖继承自父命令的参数
 }
 
 // New生成一次性解析对象。name：应用名称，desc：应用描述，用于生成usage
@@ -84,19 +208,80 @@ func putCmd(ctx context.Context, cmd *FlagSet) context.Context {
 	return context.WithValue(ctx, ctxKey, cmd)
 }
 
+var runKey = new(int)
+
+// invocation：一次Run的调用信息
+type invocation struct {
+	cmd  *FlagSet // 匹配到的命令
+	raw  []string // 传给Run的原始参数
+	args []string // 匹配到的命令的位置参数
+	err  error    // HandleE返回的错误
+}
+
+func getRun(ctx context.Context) *invocation {
+	inv, _ := ctx.Value(runKey).(*invocation)
+	return inv
+}
+
+// CommandFromContext：当前执行的命令，即匹配到的命令，可在Handler及中间件中获取命令名称、描述、参数值等。
+// 不在Run执行过程中时，返回中间件所属的命令，均没有时返回nil。
+func CommandFromContext(ctx context.Context) *FlagSet {
+	if inv := getRun(ctx); inv != nil {
+		return inv.cmd
+	}
+	return getCmd(ctx)
+}
+
+// CommandPath：匹配到的命令的完整名称，如"app remote add"。
+func CommandPath(ctx context.Context) string {
+	if inv := getRun(ctx); inv != nil {
+		return inv.cmd.fullName()
+	}
+	return ""
+}
+
+// RawArgs：传给Run的原始参数。
+func RawArgs(ctx context.Context) []string {
+	if inv := getRun(ctx); inv != nil {
+		return inv.raw
+	}
+	return nil
+}
+
+// Args：匹配到的命令的位置参数原始值，见FlagSet.ArgVar。
+func Args(ctx context.Context) []string {
+	if inv := getRun(ctx); inv != nil {
+		return inv.args
+	}
+	return nil
+}
+
 // Use：设置中间件，所有以后注册的Handler会用到该中间件
 func (fs *FlagSet) Use(mws ...Middleware) *FlagSet {
+	fs.unsealed()
 	fs.mws = append(fs.mws, mws...)
 	return fs
 }
 
 // Handle：设置Handler，并可以同时设置该handler的中间件
 func (fs *FlagSet) Handle(h Handler, mws ...Middleware) {
+	fs.unsealed()
 	h = chain(fs, mws, h)
 	for f := fs; f != nil; f = f.parent {
 		h = chain(f, f.mws, h)
 	}
 	fs.fn = h
+	fs.touch()
+}
+
+// HandleE：同Handle，h返回的错误由Run返回，Main据此以退出码1退出。
+func (fs *FlagSet) HandleE(h func(context.Context) error, mws ...Middleware) {
+	fs.Handle(func(ctx context.Context) {
+		err := h(ctx)
+		if inv := getRun(ctx); inv != nil {
+			inv.err = err
+		}
+	}, mws...)
 }
 
 func chain(fs *FlagSet, mws []Middleware, h Handler) Handler {
@@ -104,8 +289,8 @@ func chain(fs *FlagSet, mws []Middleware, h Handler) Handler {
 		n := i
 		next := h
 		h = func(ctx context.Context) {
-			if v := getCmd(ctx); v != fs {
-				ctx = putCmd(ctx, fs)
+			if v := getCmd(ctx); v != fs && (v == nil || v.origin != fs) {
+				ctx = putCmd(ctx, fs.counterpart(ctx))
 			}
 			fs.mws[n](ctx, next)
 		}
@@ -113,18 +298,132 @@ func chain(fs *FlagSet, mws []Middleware, h Handler) Handler {
 	return h
 }
 
+// Exec：同Run，额外返回匹配到的命令，出错时为出错的命令，便于测试及嵌入其它框架。
+func (fs *FlagSet) Exec(ctx context.Context, args ...string) (*MatchedCommand, error) {
+	m := new(MatchedCommand)
+	_, _, err := fs.run(ctx, args, m)
+	return m, err
+}
+
 // Run：解析参数，并调用子命令handler。常见用法为：`fs.Run(context.Background(), os.Args[1:]...)`。
-// 返回Usage及错误信息。Usage保持不为空，业务可根据需要判断是否需要展示Usage。
+// 出错时返回Usage及错误信息，Usage保持不为空，业务可根据需要判断是否需要展示Usage。
+// 执行成功时不生成Usage，返回空字符串，避免大命令树在正常路径上白白拼接帮助信息。
 func (fs *FlagSet) Run(ctx context.Context, args ...string) (string, error) {
-	f, err := fs.parse(args)
+	usage, _, err := fs.run(ctx, args, nil)
+	return usage, err
+}
+
+// run：同Run，handled表示err是否来自命令执行（HandleE或外部插件），而非参数解析。
+// m不为nil时，记录匹配到的命令。开启ChainCommands时，先解析全部命令，再依次执行。
+func (fs *FlagSet) run(ctx context.Context, args []string, m *MatchedCommand) (usage string, handled bool, err error) {
+	fs.root().warnings = nil
+	args, err = fs.preprocess(args)
 	if err != nil {
-		return f.Usage(), err
+		return fs.Usage(), false, err
+	}
+	if err = fs.checkArgsLimit(args); err != nil {
+		return fs.Usage(), false, err
+	}
+	if err = fs.loadConfig(); err != nil {
+		return fs.Usage(), false, err
+	}
+	segs := fs.root().splitChain(args)
+	var one [1]step // 未开启命令串联时避免分配
+	steps := one[:0]
+	for _, seg := range segs {
+		a := newArgs(seg...)
+		f, err := fs._parse(a)
+		if m != nil {
+			*m = MatchedCommand{Command: f, Path: f.fullName(), Args: a.positional}
+		}
+		if err != nil {
+			return f.Usage(), false, err
+		}
+		for _, s := range steps {
+			if s.cmd == f {
+				return f.Usage(), false, fmt.Errorf("%v: command %v repeated in chain", fs.name, f.fullName())
+			}
+		}
+		if a.printConfig {
+			return "", false, f.WriteEffectiveConfig(f.Stdout())
+		}
+		if a.printChanged {
+			return "", false, f.WriteChanged(f.Stdout())
+		}
+		if a.exportEnv {
+			return "", false, f.WriteExportEnv(f.Stdout())
+		}
+		if f.plugin == "" && f.fn == nil {
+			return f.Usage(), false, fmt.Errorf("flags: %w of command %v", ErrNoExecFunc, f.fullName())
+		}
+		steps = append(steps, step{cmd: f, raw: seg, args: a.positional})
+	}
+
+	for _, s := range steps {
+		if handled, err = s.exec(ctx); err != nil {
+			if !handled { // 如路径参数检查失败，同参数错误
+				usage = s.cmd.Usage()
+			}
+			return usage, handled, err
+		}
+	}
+	if root := fs.root(); root.rememberLast && root.dryRun == nil {
+		fs.saveLast(steps)
+	}
+	return "", true, nil
+}
+
+// step：解析完成、待执行的一条命令。
+type step struct {
+	cmd  *FlagSet
+	raw  []string
+	args []string
+}
+
+func (s step) exec(ctx context.Context) (handled bool, err error) {
+	f := s.cmd
+	if w := f.root().dryRun; w != nil {
+		return false, f.writeDryRun(w, s.args)
+	}
+	if f.deprecated != nil {
+		f.notice("", "command is deprecated%v", deprecatedHint(*f.deprecated))
+	}
+	if f.plugin != "" {
+		return true, f.runPlugin(ctx)
+	}
+	if err = f.checkPaths(); err != nil {
+		return false, err
 	}
-	if f.fn == nil {
-		return f.Usage(), fmt.Errorf("flags: %w of command %v", ErrNoExecFunc, f.fullName())
+	closeAll, err := f.openResources()
+	if err != nil {
+		return true, err
 	}
-	f.fn(ctx)
-	return f.Usage(), nil
+	failed := true
+	defer func() {
+		if cerr := closeAll(failed); err == nil {
+			err = cerr
+		}
+	}()
+
+	inv := &invocation{cmd: f, raw: s.raw, args: s.args}
+	f.fn(context.WithValue(ctx, runKey, inv))
+	failed = inv.err != nil
+	return true, inv.err
+}
+
+// Name：命令名称。
+func (fs *FlagSet) Name() string {
+	return fs.name
+}
+
+// FullName：命令的完整名称，如"app remote add"，同CommandPath。
+func (fs *FlagSet) FullName() string {
+	return fs.fullName()
+}
+
+// Description：命令描述。
+func (fs *FlagSet) Description() string {
+	return fs.desc
 }
 
 func (fs *FlagSet) fullName() string {
@@ -141,30 +440,100 @@ func (fs *FlagSet) fullName() string {
 	return strings.Join(names, " ")
 }
 
-// Usage：生成help信息。
+// Usage：生成help信息。生成结果会被缓存，注册参数、子命令或修改其属性后重新生成；
+// 开启PathPlugins时，插件随$PATH变化，不缓存。
 func (fs *FlagSet) Usage() string {
+	if usage, ok := fs.cachedUsage(); ok {
+		return usage
+	}
 	w := new(bytes.Buffer)
+	fs.writeUsage(w)
+	usage := string(bytes.TrimSpace(w.Bytes()))
+	if root := fs.root(); !root.pathPlugins {
+		fs.usage, fs.usageVer = usage, root.version
+	}
+	return usage
+}
 
-	name := fs.fullName()
-	fmt.Fprintf(w, "%v - %v\n\n", name, fs.desc)
+// cachedUsage：仍然有效的缓存的帮助信息。
+func (fs *FlagSet) cachedUsage() (string, bool) {
+	root := fs.root()
+	if fs.usage == "" || fs.usageVer != root.version || root.pathPlugins {
+		return "", false
+	}
+	return fs.usage, true
+}
 
-	fmt.Fprintf(w, "Usage:\n")
-	fmt.Fprintf(w, "  %v", name)
-	if fs.fn != nil && len(fs.params) > 0 {
-		if len(fs.cmds) > 0 {
-			fmt.Fprintf(w, " [option|command]")
-		} else {
-			fmt.Fprintf(w, " [option]")
+// touch：注册信息发生变化，使缓存的帮助信息失效。
+func (fs *FlagSet) touch() {
+	fs.unsealed()
+	fs.root().version++
+}
+
+// WriteUsage：将help信息直接写入w，内容同Usage，并以换行结尾。
+// 适用于包含大量子命令的帮助信息，无需先在内存中拼接完整字符串。
+func (fs *FlagSet) WriteUsage(w io.Writer) error {
+	if usage, ok := fs.cachedUsage(); ok {
+		_, err := io.WriteString(w, usage+"\n")
+		return err
+	}
+	tw := &trimWriter{w: w}
+	fs.writeUsage(tw)
+	if tw.err == nil {
+		_, tw.err = io.WriteString(w, "\n")
+	}
+	return tw.err
+}
+
+// trimWriter：去掉末尾换行符的Writer，连续的换行符在后续有内容写入时才写出。
+type trimWriter struct {
+	w       io.Writer
+	pending int // 尚未写出的换行符个数
+	err     error
+}
+
+func (tw *trimWriter) Write(p []byte) (int, error) {
+	if tw.err != nil {
+		return 0, tw.err
+	}
+	body := bytes.TrimRight(p, "\n")
+	if len(body) == 0 {
+		tw.pending += len(p)
+		return len(p), nil
+	}
+	if tw.pending > 0 {
+		if _, tw.err = tw.w.Write(bytes.Repeat([]byte{'\n'}, tw.pending)); tw.err != nil {
+			return 0, tw.err
 		}
-	} else if len(fs.cmds) > 0 {
-		fmt.Fprintf(w, " [command]")
 	}
+	if _, tw.err = tw.w.Write(body); tw.err != nil {
+		return 0, tw.err
+	}
+	tw.pending = len(p) - len(body)
+	return len(p), nil
+}
+
+func (fs *FlagSet) writeUsage(w io.Writer) {
+	if fs.root().plainUsage {
+		fs.writePlainUsage(w)
+		return
+	}
+
+	name := fs.localFullName()
+	fmt.Fprintf(w, "%v - %v\n\n", name, fs.localDesc())
+	if fs.deprecated != nil {
+		fmt.Fprintf(w, "Deprecated%v\n\n", deprecatedHint(*fs.deprecated))
+	}
+
+	fmt.Fprintf(w, "Usage:\n")
+	fmt.Fprintf(w, "  %v", name)
+	fs.writeSynopsis(w)
 	fmt.Fprintf(w, "\n\n")
 
-	if fs.fn != nil && len(fs.params) > 0 {
+	if fs.hasOptions() {
 		fmt.Fprintf(w, "Options:\n")
 
-		for _, p := range fs.params {
+		for _, p := range fs.allParams() {
 			fmt.Fprintf(w, "  ")
 			if p.short != "" {
 				fmt.Fprintf(w, "-%v", p.short)
@@ -173,19 +542,35 @@ func (fs *FlagSet) Usage() string {
 				if p.short != "" {
 					fmt.Fprintf(w, ", ")
 				}
-				fmt.Fprintf(w, "--%v", p.long)
-			}
-			fmt.Fprintf(w, " %v", p.typ)
-			if p.dft != nil {
-				if t, ok := p.dft.(time.Time); ok {
-					fmt.Fprintf(w, " (default: %q)", t.Format(DateTime))
-				} else if s, ok := p.dft.(string); ok {
-					fmt.Fprintf(w, " (default: %q)", s)
+				if p.negatable {
+					fmt.Fprintf(w, "--[no-]%v", p.long)
 				} else {
-					fmt.Fprintf(w, " (default: %v)", p.dft)
+					fmt.Fprintf(w, "--%v", p.long)
+				}
+			}
+			writeUsageValue(w, p)
+			fmt.Fprintln(w)
+			if p.desc != "" {
+				for _, line := range strings.Split(p.desc, "\n") {
+					fmt.Fprintf(w, "    %v\n", line)
 				}
 			}
 			fmt.Fprintln(w)
+		}
+		for _, opt := range fs.builtinOptions() {
+			fmt.Fprintf(w, "  %v\n    %v\n\n", opt.name, opt.desc)
+		}
+	}
+
+	if fs.fn != nil && (len(fs.posArgs) > 0 || fs.rest != nil) {
+		fmt.Fprintf(w, "Arguments:\n")
+		for _, p := range fs.allArgs() {
+			fmt.Fprintf(w, "  %v", p.arg)
+			writeUsageValue(w, p)
+			if p == fs.rest && fs.restMin > 0 {
+				fmt.Fprintf(w, " (min: %v)", fs.restMin)
+			}
+			fmt.Fprintln(w)
 			if p.desc != "" {
 				for _, line := range strings.Split(p.desc, "\n") {
 					fmt.Fprintf(w, "    %v\n", line)
@@ -198,9 +583,18 @@ func (fs *FlagSet) Usage() string {
 	if len(fs.cmds) > 0 {
 		fmt.Fprintf(w, "Commands:\n")
 		for _, cmd := range fs.cmds {
-			fmt.Fprintf(w, "  %v\n", cmd.name)
-			if cmd.desc != "" {
-				for _, line := range strings.Split(cmd.desc, "\n") {
+			fmt.Fprintf(w, "  %v", cmd.localName())
+			if l, ok := cmd.localized(); ok {
+				for _, alias := range l.aliases {
+					fmt.Fprintf(w, ", %v", alias)
+				}
+			}
+			if cmd.deprecated != nil {
+				fmt.Fprintf(w, " (deprecated%v)", deprecatedHint(*cmd.deprecated))
+			}
+			fmt.Fprintln(w)
+			if desc := cmd.localDesc(); desc != "" {
+				for _, line := range strings.Split(desc, "\n") {
 					fmt.Fprintf(w, "    %v\n", line)
 				}
 			}
@@ -208,86 +602,276 @@ func (fs *FlagSet) Usage() string {
 		}
 	}
 
-	return string(bytes.TrimSpace(w.Bytes()))
+	if fs.root().pathPlugins {
+		if plugins := fs.discoverPlugins(); len(plugins) > 0 {
+			fmt.Fprintf(w, "Plugins:\n")
+			for _, name := range plugins {
+				fmt.Fprintf(w, "  %v\n", name)
+			}
+		}
+	}
+
+}
+
+// builtinOption：Usage中列出的内置参数。
+type builtinOption struct {
+	name string
+	desc string
+}
+
+// builtinOptions：已开启的需在Usage中列出的内置参数，见PrintConfig、PrintChanged、RememberLast、ExportEnv。
+func (fs *FlagSet) builtinOptions() []builtinOption {
+	var opts []builtinOption
+	root := fs.root()
+	if root.printConfig {
+		opts = append(opts, builtinOption{"--print-config", "print the effective flag values with their sources"})
+	}
+	if root.printChanged {
+		opts = append(opts, builtinOption{"--print-changed", "print the flags whose values differ from their defaults"})
+	}
+	if root.rememberLast {
+		opts = append(opts, builtinOption{"--last", "use the flags of the last successful run for flags not given"})
+	}
+	if root.exportEnv {
+		opts = append(opts, builtinOption{"--export-env", "print the flags bound to env as shell export statements"})
+	}
+	return opts
+}
+
+// hasOptions：Usage中是否有Options部分。
+func (fs *FlagSet) hasOptions() bool {
+	return fs.fn != nil && (fs.numParams() > 0 || len(fs.builtinOptions()) > 0)
+}
+
+// writeSynopsis：Usage中命令名称之后的部分，如" [option|command] [file]"。
+func (fs *FlagSet) writeSynopsis(w io.Writer) {
+	if fs.hasOptions() {
+		if len(fs.cmds) > 0 {
+			fmt.Fprintf(w, " [option|command]")
+		} else {
+			fmt.Fprintf(w, " [option]")
+		}
+	} else if len(fs.cmds) > 0 {
+		fmt.Fprintf(w, " [command]")
+	}
+	if fs.fn != nil {
+		for _, p := range fs.posArgs {
+			fmt.Fprintf(w, " [%v]", p.arg)
+		}
+		if fs.rest != nil {
+			if fs.restMin > 0 {
+				fmt.Fprintf(w, " %v...", fs.rest.arg)
+			} else {
+				fmt.Fprintf(w, " [%v...]", fs.rest.arg)
+			}
+		}
+	}
+}
+
+// writeUsageValue：Usage中参数名称之后的部分，包括参数值类型、默认值等。
+func writeUsageValue(w io.Writer, p *param) {
+	if p.meta != "" {
+		fmt.Fprintf(w, " %v", p.meta)
+	} else {
+		fmt.Fprintf(w, " %v", p.typ)
+	}
+	if p.greedy {
+		fmt.Fprintf(w, "...")
+	}
+	if p.noOpt != nil {
+		fmt.Fprintf(w, "[=%q]", *p.noOpt)
+	}
+	for _, n := range usageNotes(p) {
+		if n.value == "" {
+			fmt.Fprintf(w, " (%v)", n.label)
+		} else {
+			fmt.Fprintf(w, " (%v: %v)", n.label, n.value)
+		}
+	}
+}
+
+// usageNote：Usage中参数的附加说明，如默认值、绑定的环境变量等，value为空时只显示label。
+type usageNote struct {
+	label string
+	value string
+}
+
+func usageNotes(p *param) []usageNote {
+	var notes []usageNote
+	if p.dft != nil {
+		var dft string
+		if t, ok := p.dft.(time.Time); ok {
+			dft = strconv.Quote(t.Format(DateTime))
+		} else if s, ok := p.dft.(string); ok {
+			dft = strconv.Quote(s)
+		} else if m, ok := p.dft.(os.FileMode); ok {
+			dft = fmt.Sprintf("%#o", m)
+		} else if t, ok := p.dft.(*template.Template); ok && t.Tree != nil {
+			dft = strconv.Quote(t.Root.String())
+		} else if r, ok := p.dft.(*big.Rat); ok {
+			dft = formatDecimal(r)
+		} else {
+			dft = fmt.Sprint(p.dft)
+		}
+		notes = append(notes, usageNote{"default", dft})
+	}
+	if p.unit > 0 {
+		notes = append(notes, usageNote{"unit", p.unit.String()})
+	}
+	if p.scaled {
+		notes = append(notes, usageNote{"scaled", ""})
+	}
+	if p.isSlice() && p.arg == "" {
+		notes = append(notes, usageNote{"mode", p.mode.String()})
+	}
+	if p.env != "" {
+		notes = append(notes, usageNote{"env", p.env})
+	}
+	if p.deprecated != nil {
+		notes = append(notes, usageNote{"deprecated", *p.deprecated})
+	}
+	return notes
 }
 
 // Stmt：开启一个单独的状态，可用于注册特定中间件，不影响Stmt之后的命令。
 func (fs *FlagSet) Stmt(mws ...Middleware) *FlagSet {
-	params := make([]*param, len(fs.params))
-	copy(params, fs.params)
-
+	fs.unsealed()
 	s := &FlagSet{
 		desc:   fs.desc,
-		params: params,
 		mws:    mws,
 		parent: fs,
 	}
+	fs.bequeath(s)
 	if fs.stmt != nil {
 		s.stmt = fs.stmt
 	} else {
 		s.stmt = fs
 	}
+	fs.stmts = append(fs.stmts, s)
 	return s
 }
 
 // Cmd：注册子命令，及子命令用到的中间件。
 func (fs *FlagSet) Cmd(name, desc string, mws ...Middleware) *FlagSet {
+	return fs.cmd(name, desc, true, mws)
+}
+
+// CmdLocal：注册子命令，与Cmd不同的是，子命令不继承当前命令已注册的参数，适用于父命令参数对子命令无意义的场景。
+// 父命令的中间件仍然生效，其使用的父命令参数取默认值。
+func (fs *FlagSet) CmdLocal(name, desc string, mws ...Middleware) *FlagSet {
+	return fs.cmd(name, desc, false, mws)
+}
+
+func (fs *FlagSet) cmd(name, desc string, inherit bool, mws []Middleware) *FlagSet {
+	fs.unsealed()
 	if name == "" {
 		panic(fmt.Errorf("flags: subcommand name cannot be empty"))
 	}
-	for _, cmd := range fs.cmds {
-		if cmd.name == name {
-			panic(fmt.Errorf("flags: duplicated subcommand: %v", name))
-		}
+	owner := fs
+	if fs.stmt != nil {
+		owner = fs.stmt
+	}
+	if owner.cmdIdx[name] != nil {
+		panic(fmt.Errorf("flags: duplicated subcommand: %v", name))
 	}
-
-	params := make([]*param, len(fs.params))
-	copy(params, fs.params)
 
 	cmd := &FlagSet{
 		name:   name,
 		desc:   desc,
-		params: params,
 		mws:    mws,
 		parent: fs,
 	}
-	if fs.stmt != nil {
-		fs.stmt.cmds = append(fs.stmt.cmds, cmd)
-	} else {
-		fs.cmds = append(fs.cmds, cmd)
+	if inherit {
+		fs.bequeath(cmd)
+	}
+	owner.cmds = append(owner.cmds, cmd)
+	if owner.cmdIdx == nil {
+		owner.cmdIdx = make(map[string]*FlagSet)
 	}
+	owner.cmdIdx[name] = cmd
+	owner.localIdx = nil
+	fs.touch()
 	return cmd
 }
 
-func (fs *FlagSet) addVar(ptr any, shortByte byte, long string, dft any, desc string, seperator ...string) {
+func (fs *FlagSet) addVar(ptr any, shortRune rune, long string, dft any, desc string, seperator ...string) *param {
+	fs.unsealed()
 	var short string
-	if shortByte != NoShort {
-		if !ValidShort(shortByte) {
-			panic(fmt.Errorf("flags: invalid short option: %c", shortByte))
+	if shortRune != NoShort {
+		if !ValidShort(shortRune) {
+			panic(fmt.Errorf("flags: invalid short option: %c", shortRune))
 		}
-		short = string(shortByte)
+		short = string(shortRune)
 	}
-	long = strings.TrimLeft(long, "-")
+	long = fs.normalizeName(strings.TrimLeft(long, "-"))
 	if !ValidLong(long) {
 		panic(fmt.Errorf("flags: invalid long option: %q", long))
 	}
 
-	for _, p := range fs.params {
-		if short != "" && p.short == short {
-			panic(fmt.Errorf("flags: duplicated short option: -%v", short))
+	// 与继承自父命令的参数重名时，新参数覆盖父命令参数，仅在当前命令及之后注册的子命令中生效。
+	if fs.findParam(func(p *param) bool {
+		return short != "" && p.short == short || long != "" && (p.long == long || p.negatable && "no-"+p.long == long)
+	}) != nil {
+		fs.materialize()
+	}
+	shadow := -1
+	for i := 0; i < len(fs.params); i++ {
+		p := fs.params[i]
+		dupShort := short != "" && p.short == short
+		dupLong := long != "" && (p.long == long || p.negatable && "no-"+p.long == long)
+		if !dupShort && !dupLong {
+			continue
 		}
-		if long != "" && p.long == long {
+		if p.owner == fs {
+			if dupShort {
+				panic(fmt.Errorf("flags: duplicated short option: -%v", short))
+			}
 			panic(fmt.Errorf("flags: duplicated long option: --%v", long))
 		}
+		if shadow < 0 {
+			shadow = i
+			continue
+		}
+		fs.params = append(fs.params[:i], fs.params[i+1:]...)
+		i--
+	}
+
+	fs.idx = nil
+	fs.touch()
+	p := newVar(ptr, dft, seperator...)
+	p.short = short
+	p.long = long
+	p.desc = desc
+	p.owner = fs
+	if p.rtyp == typTemplate {
+		p.set = setTemplate(p.configKey())
+	}
+	if shadow >= 0 {
+		fs.params[shadow] = p
+	} else {
+		fs.params = append(fs.params, p)
 	}
+	return p
+}
 
+// newVar：根据变量指针及默认值生成param，检查默认值类型。
+func newVar(ptr any, dft any, seperator ...string) *param {
 	if typ := reflect.TypeOf(ptr); typ.Kind() != reflect.Pointer {
 		panic(fmt.Errorf("flags: var type %v must be a pointer", typ))
 	}
 
+	var dftFn reflect.Value
 	if dft != nil {
 		if dv := reflect.ValueOf(dft); dv.IsZero() {
 			dft = nil
+		} else if dv.Kind() == reflect.Func {
+			t1 := reflect.TypeOf(ptr).Elem()
+			t2 := dv.Type()
+			if t2.NumIn() != 0 || t2.NumOut() != 1 || t2.Out(0) != t1 {
+				panic(fmt.Errorf("flags: default func type %v must be func() %v", t2, t1))
+			}
+			dftFn = dv
+			dft = nil
 		} else {
 			t1 := reflect.TypeOf(ptr).Elem()
 			t2 := reflect.TypeOf(dft)
@@ -297,14 +881,6 @@ func (fs *FlagSet) addVar(ptr any, shortByte byte, long string, dft any, desc st
 		}
 	}
 
-	typ := reflect.TypeOf(ptr).Elem().String()
-	switch typ {
-	case "time.Duration":
-		typ = "duration"
-	case "time.Time":
-		typ = fmt.Sprintf("datetime, format: %q", DateTime)
-	}
-
 	sep1 := ","
 	if len(seperator) > 0 && seperator[0] != "" {
 		sep1 = seperator[0]
@@ -313,16 +889,57 @@ func (fs *FlagSet) addVar(ptr any, shortByte byte, long string, dft any, desc st
 	if len(seperator) > 1 && seperator[1] != "" {
 		sep2 = seperator[1]
 	}
-	fs.params = append(fs.params, &param{
-		ptr:   ptr,
-		typ:   typ,
-		dft:   dft,
-		short: short,
-		long:  strings.TrimLeft(long, "-"),
-		desc:  desc,
-		sep1:  sep1,
-		sep2:  sep2,
-	})
+	sep3 := "."
+	if len(seperator) > 2 && seperator[2] != "" {
+		sep3 = seperator[2]
+	}
+
+	p := newParam(reflect.TypeOf(ptr).Elem(), sep1, sep2, sep3)
+	switch p.rtyp {
+	case typDuration:
+		p.typ = "duration"
+	case typDateTime:
+		p.typ = fmt.Sprintf("datetime, format: %q", DateTime)
+	case typLocation:
+		p.typ = "location"
+	case typHardwareAddr:
+		p.typ = "mac"
+	case typFileMode:
+		p.typ = "filemode"
+	case typLogLevel:
+		p.typ = "loglevel"
+	case typTemplate:
+		p.typ = "template"
+	}
+	p.ptr = ptr
+	p.dft = dft
+	p.dftFn = dftFn
+	return p
+}
+
+// newParam：根据变量类型生成param，并缓存类型信息，以及slice/map元素的解析参数，避免每次解析重复分析类型。
+func newParam(rtyp reflect.Type, sep1, sep2, sep3 string) *param {
+	p := &param{
+		typ:  rtyp.String(),
+		rtyp: rtyp,
+		kind: rtyp.Kind(),
+		sep1: sep1,
+		sep2: sep2,
+		sep3: sep3,
+	}
+	switch {
+	case p.isSlice():
+		et := rtyp.Elem()
+		if et.Kind() == reflect.Pointer {
+			p.elemPtr = true
+			et = et.Elem()
+		}
+		p.elem = newParam(et, sep1, sep2, sep3)
+	case p.kind == reflect.Map:
+		p.key = newParam(rtyp.Key(), sep1, sep2, sep3)
+		p.elem = newParam(rtyp.Elem(), sep1, sep2, sep3)
+	}
+	return p
 }
 
 func isNumber(b byte) bool {
@@ -338,8 +955,9 @@ func isSymbol(b byte) bool {
 	return b == '-' || b == '_' || b == '.'
 }
 
-func ValidShort(short byte) bool {
-	return short == NoShort || isNumber(short) || isLetter(short)
+// ValidShort：短参数须为字母或数字，支持非ASCII字符，如'é'、'名'。
+func ValidShort(short rune) bool {
+	return short == NoShort || unicode.IsLetter(short) || unicode.IsDigit(short)
 }
 
 func ValidLong(long string) bool {
@@ -359,241 +977,451 @@ func ValidLong(long string) bool {
 	return true
 }
 
-func (fs *FlagSet) Int(short byte, long string, dft int, desc string) *int {
+func (fs *FlagSet) Int(short rune, long string, dft int, desc string) *int {
 	ptr := new(int)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.IntVar(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) IntVar(ptr *int, short byte, long string, dft int, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) IntVar(ptr *int, short rune, long string, dft int, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseInt[int])
 }
 
-func (fs *FlagSet) Int8(short byte, long string, dft int8, desc string) *int8 {
+func (fs *FlagSet) Int8(short rune, long string, dft int8, desc string) *int8 {
 	ptr := new(int8)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Int8Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Int8Var(ptr *int8, short byte, long string, dft int8, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Int8Var(ptr *int8, short rune, long string, dft int8, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseInt[int8])
 }
 
-func (fs *FlagSet) Int16(short byte, long string, dft int16, desc string) *int16 {
+func (fs *FlagSet) Int16(short rune, long string, dft int16, desc string) *int16 {
 	ptr := new(int16)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Int16Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Int16Var(ptr *int16, short byte, long string, dft int16, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Int16Var(ptr *int16, short rune, long string, dft int16, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseInt[int16])
 }
 
-func (fs *FlagSet) Int32(short byte, long string, dft int32, desc string) *int32 {
+func (fs *FlagSet) Int32(short rune, long string, dft int32, desc string) *int32 {
 	ptr := new(int32)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Int32Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Int32Var(ptr *int32, short byte, long string, dft int32, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Int32Var(ptr *int32, short rune, long string, dft int32, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseInt[int32])
 }
 
-func (fs *FlagSet) Int64(short byte, long string, dft int64, desc string) *int64 {
+func (fs *FlagSet) Int64(short rune, long string, dft int64, desc string) *int64 {
 	ptr := new(int64)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Int64Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Int64Var(ptr *int64, short byte, long string, dft int64, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Int64Var(ptr *int64, short rune, long string, dft int64, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseInt[int64])
 }
 
-func (fs *FlagSet) Uint(short byte, long string, dft uint, desc string) *uint {
+func (fs *FlagSet) Uint(short rune, long string, dft uint, desc string) *uint {
 	ptr := new(uint)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.UintVar(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) UintVar(ptr *uint, short byte, long string, dft uint, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) UintVar(ptr *uint, short rune, long string, dft uint, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseUint[uint])
 }
 
-func (fs *FlagSet) Uint8(short byte, long string, dft uint8, desc string) *uint8 {
+func (fs *FlagSet) Uint8(short rune, long string, dft uint8, desc string) *uint8 {
 	ptr := new(uint8)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Uint8Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Uint8Var(ptr *uint8, short byte, long string, dft uint8, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Uint8Var(ptr *uint8, short rune, long string, dft uint8, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseUint[uint8])
 }
 
-func (fs *FlagSet) Uint16(short byte, long string, dft uint16, desc string) *uint16 {
+func (fs *FlagSet) Uint16(short rune, long string, dft uint16, desc string) *uint16 {
 	ptr := new(uint16)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Uint16Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Uint16Var(ptr *uint16, short byte, long string, dft uint16, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Uint16Var(ptr *uint16, short rune, long string, dft uint16, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseUint[uint16])
 }
 
-func (fs *FlagSet) Uint32(short byte, long string, dft uint32, desc string) *uint32 {
+func (fs *FlagSet) Uint32(short rune, long string, dft uint32, desc string) *uint32 {
 	ptr := new(uint32)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Uint32Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Uint32Var(ptr *uint32, short byte, long string, dft uint32, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Uint32Var(ptr *uint32, short rune, long string, dft uint32, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseUint[uint32])
 }
 
-func (fs *FlagSet) Uint64(short byte, long string, dft uint64, desc string) *uint64 {
+func (fs *FlagSet) Uint64(short rune, long string, dft uint64, desc string) *uint64 {
 	ptr := new(uint64)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Uint64Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Uint64Var(ptr *uint64, short byte, long string, dft uint64, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Uint64Var(ptr *uint64, short rune, long string, dft uint64, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseUint[uint64])
 }
 
-func (fs *FlagSet) Float32(short byte, long string, dft float32, desc string) *float32 {
+func (fs *FlagSet) Float32(short rune, long string, dft float32, desc string) *float32 {
 	ptr := new(float32)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Float32Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Float32Var(ptr *float32, short byte, long string, dft float32, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Float32Var(ptr *float32, short rune, long string, dft float32, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseFloat32)
 }
 
-func (fs *FlagSet) Float64(short byte, long string, dft float64, desc string) *float64 {
+func (fs *FlagSet) Float64(short rune, long string, dft float64, desc string) *float64 {
 	ptr := new(float64)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.Float64Var(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) Float64Var(ptr *float64, short byte, long string, dft float64, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) Float64Var(ptr *float64, short rune, long string, dft float64, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseFloat64)
 }
 
-func (fs *FlagSet) Str(short byte, long string, dft string, desc string) *string {
+func (fs *FlagSet) Str(short rune, long string, dft string, desc string) *string {
 	ptr := new(string)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.StrVar(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) StrVar(ptr *string, short byte, long string, dft string, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) StrVar(ptr *string, short rune, long string, dft string, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseString)
 }
 
-func (fs *FlagSet) Bool(short byte, long string, dft bool, desc string) *bool {
+func (fs *FlagSet) Bool(short rune, long string, dft bool, desc string) *bool {
 	ptr := new(bool)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.BoolVar(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) BoolVar(ptr *bool, short byte, long string, dft bool, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) BoolVar(ptr *bool, short rune, long string, dft bool, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseBool)
 }
 
-func (fs *FlagSet) Duration(short byte, long string, dft time.Duration, desc string) *time.Duration {
+func (fs *FlagSet) Duration(short rune, long string, dft time.Duration, desc string) *time.Duration {
 	ptr := new(time.Duration)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.DurationVar(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) DurationVar(ptr *time.Duration, short byte, long string, dft time.Duration, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) DurationVar(ptr *time.Duration, short rune, long string, dft time.Duration, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, ParseDuration)
 }
 
-func (fs *FlagSet) DateTime(short byte, long string, dft time.Time, desc string) *time.Time {
+func (fs *FlagSet) DateTime(short rune, long string, dft time.Time, desc string) *time.Time {
 	ptr := new(time.Time)
-	fs.addVar(ptr, short, long, dft, desc)
+	fs.DateTimeVar(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func (fs *FlagSet) DateTimeVar(ptr *time.Time, short byte, long string, dft time.Time, desc string) {
-	fs.addVar(ptr, short, long, dft, desc)
-}
-
-// AnyVar: add any pointer to parse.
-// param ptr must be a pointer,
-// param dft should be nil if no default value,
-// or else dft type must be reflect.TypeOf(ptr).Elem().
-func (fs *FlagSet) AnyVar(ptr any, short byte, long string, dft any, desc string, seperator ...string) {
-	fs.addVar(ptr, short, long, dft, desc)
+func (fs *FlagSet) DateTimeVar(ptr *time.Time, short rune, long string, dft time.Time, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, fs.parseDateTime)
 }
 
-type KeyTypes interface {
-	~int | ~int8 | ~int16 | ~int32 | ~int64 |
-		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
-		~float32 | ~float64 |
-		~bool |
-		~string
+func (fs *FlagSet) Location(short rune, long string, dft *time.Location, desc string) **time.Location {
+	ptr := new(*time.Location)
+	fs.LocationVar(ptr, short, long, dft, desc)
+	return ptr
 }
 
-type ElemTypes interface {
-	KeyTypes | time.Time
+// LocationVar：时区参数，通过time.LoadLocation解析，如"--tz Asia/Shanghai"。
+func (fs *FlagSet) LocationVar(ptr **time.Location, short rune, long string, dft *time.Location, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, time.LoadLocation)
 }
 
-type ComTypes[K KeyTypes, V ElemTypes] interface {
-	[]V | map[K]V | []map[K]V | map[K][]V
+func (fs *FlagSet) MAC(short rune, long string, dft net.HardwareAddr, desc string) *net.HardwareAddr {
+	ptr := new(net.HardwareAddr)
+	fs.MACVar(ptr, short, long, dft, desc)
+	return ptr
 }
 
-type Types[K KeyTypes, V ElemTypes] interface {
-	ElemTypes | ComTypes[K, V]
+// MACVar：MAC地址参数，通过net.ParseMAC解析，如"--mac 00:00:5e:00:53:01"。
+func (fs *FlagSet) MACVar(ptr *net.HardwareAddr, short rune, long string, dft net.HardwareAddr, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, net.ParseMAC)
 }
 
-func Any[T Types[K, V], K KeyTypes, V ElemTypes](fs *FlagSet, short byte, long string, dft T, desc string, seperator ...string) *T {
-	ptr := new(T)
-	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
+func (fs *FlagSet) FileMode(short rune, long string, dft os.FileMode, desc string) *os.FileMode {
+	ptr := new(os.FileMode)
+	fs.FileModeVar(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func AnyVar[T Types[K, V], K KeyTypes, V ElemTypes](fs *FlagSet, ptr *T, short byte, long string, dft T, desc string, seperator ...string) {
-	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
+// FileModeVar：文件权限参数，按八进制解析，如"--mode 0755"，Usage中默认值也以八进制显示。
+func (fs *FlagSet) FileModeVar(ptr *os.FileMode, short rune, long string, dft os.FileMode, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseFileMode)
 }
 
-func Slice[T ElemTypes](fs *FlagSet, short byte, long string, dft []T, desc string, seperator ...string) *[]T {
-	ptr := new([]T)
-	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
+func (fs *FlagSet) LogLevel(short rune, long string, dft slog.Level, desc string) *slog.Level {
+	ptr := new(slog.Level)
+	fs.LogLevelVar(ptr, short, long, dft, desc)
 	return ptr
 }
 
-func SliceVar[T ElemTypes](fs *FlagSet, ptr *[]T, short byte, long string, dft []T, desc string, seperator ...string) {
+// LogLevelVar：日志级别参数，支持debug/info/warn/error（不区分大小写，可带偏移如"info+2"）及数字。
+func (fs *FlagSet) LogLevelVar(ptr *slog.Level, short rune, long string, dft slog.Level, desc string) {
+	addTyped(fs, ptr, short, long, dft, desc, parseLogLevel)
+}
+
+func (fs *FlagSet) Template(short rune, long string, dft *template.Template, desc string) **template.Template {
+	ptr := new(*template.Template)
+	fs.TemplateVar(ptr, short, long, dft, desc)
+	return ptr
+}
+
+// TemplateVar：输出格式模板参数，解析时通过text/template编译，如"--format '{{.Name}}: {{.Size}}'"，
+// 模板语法错误会带出错位置。每次解析均编译新的模板，之前得到的模板不受影响。
+func (fs *FlagSet) TemplateVar(ptr **template.Template, short rune, long string, dft *template.Template, desc string) {
+	fs.addVar(ptr, short, long, dft, desc)
+}
+
+// setTemplate：模板参数的赋值函数，每次赋值均编译新的模板，不同次解析及Clone的副本之间互不影响。
+func setTemplate(name string) func(ptr any, s string) error {
+	return func(ptr any, s string) error {
+		tmpl, err := template.New(name).Parse(s)
+		if err != nil {
+			return err
+		}
+		*ptr.(**template.Template) = tmpl
+		return nil
+	}
+}
+
+// addTyped：注册标量参数，并设置非反射的赋值函数，解析时无需经过reflect。
+func addTyped[T any](fs *FlagSet, ptr *T, short rune, long string, dft T, desc string, parse func(string) (T, error)) *param {
+	p := fs.addVar(ptr, short, long, dft, desc)
+	p.set = func(ptr any, s string) error {
+		v, err := parse(s)
+		if err != nil {
+			return err
+		}
+		*ptr.(*T) = v
+		return nil
+	}
+	if p.dft != nil {
+		p.setDft = func(ptr any) { *ptr.(*T) = dft }
+	}
+	return p
+}
+
+func parseInt[T ~int | ~int8 | ~int16 | ~int32 | ~int64](s string) (T, error) {
+	i, err := strconv.ParseInt(digits(s), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	v := T(i)
+	if int64(v) != i {
+		return 0, fmt.Errorf("cannot set %v to an %T, overflowed", i, v)
+	}
+	return v, nil
+}
+
+func parseUint[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](s string) (T, error) {
+	i, err := strconv.ParseUint(digits(s), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	v := T(i)
+	if uint64(v) != i {
+		return 0, fmt.Errorf("cannot set %v to an %T, overflowed", i, v)
+	}
+	return v, nil
+}
+
+func parseFloat32(s string) (float32, error) {
+	f, err := strconv.ParseFloat(digits(s), 32)
+	return float32(f), err
+}
+
+func parseFloat64(s string) (float64, error) {
+	return strconv.ParseFloat(digits(s), 64)
+}
+
+func parseString(s string) (string, error) {
+	return s, nil
+}
+
+// parseBool：除true/false外，还支持1/0、t/f、yes/no、y/n、on/off，不区分大小写。
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "t", "1", "yes", "y", "on":
+		return true, nil
+	case "false", "f", "0", "no", "n", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid bool value: %q", s)
+}
+
+func parseFileMode(s string) (os.FileMode, error) {
+	m, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if m > 07777 { // 仅权限位，更高位在os.FileMode中表示文件类型
+		return 0, fmt.Errorf("file mode %v out of range, max 07777", s)
+	}
+	return os.FileMode(m), nil
+}
+
+func parseLogLevel(s string) (slog.Level, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return slog.Level(n), nil
+	}
+	var level slog.Level
+	err := level.UnmarshalText([]byte(s))
+	return level, err
+}
+
+// parseDateTime：解析DateTime格式的时间，或相对时间表达式，见relativeTime。
+func (fs *FlagSet) parseDateTime(s string) (time.Time, error) {
+	if t, ok, err := fs.relativeTime(s); ok {
+		return t, err
+	}
+	return time.ParseInLocation(DateTime, s, time.Local)
+}
+
+// relativeTime：解析相对时间表达式，now、today、yesterday、tomorrow（不区分大小写），
+// 其后可加减时长，如"now-1h"、"today+9h"、"yesterday-1w"，时长格式见ParseDuration。
+// 当前时间取自fs.Now()，today等为当天零点。ok表示s是否为相对时间表达式。
+func (fs *FlagSet) relativeTime(s string) (t time.Time, ok bool, err error) {
+	base, offset := s, ""
+	if i := strings.IndexAny(s, "+-"); i >= 0 {
+		base, offset = s[:i], s[i:]
+	}
+
+	now := fs.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(base) {
+	case "now":
+		t = now
+	case "today":
+		t = today
+	case "yesterday":
+		t = today.AddDate(0, 0, -1)
+	case "tomorrow":
+		t = today.AddDate(0, 0, 1)
+	default:
+		return time.Time{}, false, nil
+	}
+
+	if offset != "" {
+		d, err := ParseDuration(offset)
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("invalid relative time: %q", s)
+		}
+		t = t.Add(d)
+	}
+	return t, true, nil
+}
+
+// AnyVar: add any pointer to parse.
+// param ptr must be a pointer,
+// param dft should be nil if no default value,
+// or else dft type must be reflect.TypeOf(ptr).Elem(),
+// or func() T where T is reflect.TypeOf(ptr).Elem(), which is called only when the option is not set,
+// for expensive or environment-dependent defaults such as hostname or time.Now.
+// struct (except time.Time) and []struct are parsed from JSON objects, one object per occurrence.
+func (fs *FlagSet) AnyVar(ptr any, short rune, long string, dft any, desc string, seperator ...string) {
+	fs.addVar(ptr, short, long, dft, desc, seperator...)
+}
+
+type KeyTypes interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 |
+		~bool |
+		~string
+}
+
+type ElemTypes interface {
+	KeyTypes | time.Time | net.HardwareAddr
+}
+
+type ComTypes[K KeyTypes, V ElemTypes] interface {
+	[]V | map[K]V | []map[K]V | map[K][]V | map[K]map[K]V
+}
+
+type Types[K KeyTypes, V ElemTypes] interface {
+	ElemTypes | ComTypes[K, V]
+}
+
+func Any[T Types[K, V], K KeyTypes, V ElemTypes](fs *FlagSet, short rune, long string, dft T, desc string, seperator ...string) *T {
+	ptr := new(T)
+	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
+	return ptr
+}
+
+func AnyVar[T Types[K, V], K KeyTypes, V ElemTypes](fs *FlagSet, ptr *T, short rune, long string, dft T, desc string, seperator ...string) {
+	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
+}
+
+func Slice[T ElemTypes](fs *FlagSet, short rune, long string, dft []T, desc string, seperator ...string) *[]T {
+	ptr := new([]T)
+	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
+	return ptr
+}
+
+func SliceVar[T ElemTypes](fs *FlagSet, ptr *[]T, short rune, long string, dft []T, desc string, seperator ...string) {
 	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
 }
 
-func Map[K KeyTypes, V ElemTypes](fs *FlagSet, short byte, long string, dft map[K]V, desc string, seperator ...string) *map[K]V {
+func Map[K KeyTypes, V ElemTypes](fs *FlagSet, short rune, long string, dft map[K]V, desc string, seperator ...string) *map[K]V {
 	ptr := new(map[K]V)
 	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
 	return ptr
 }
 
-func MapVar[K KeyTypes, V ElemTypes](fs *FlagSet, ptr *map[K]V, short byte, long string, dft map[K]V, desc string, seperator ...string) {
+func MapVar[K KeyTypes, V ElemTypes](fs *FlagSet, ptr *map[K]V, short rune, long string, dft map[K]V, desc string, seperator ...string) {
 	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
 }
 
-func SliceMap[K KeyTypes, V ElemTypes](fs *FlagSet, short byte, long string, dft []map[K]V, desc string, seperator ...string) *[]map[K]V {
+func SliceMap[K KeyTypes, V ElemTypes](fs *FlagSet, short rune, long string, dft []map[K]V, desc string, seperator ...string) *[]map[K]V {
 	ptr := new([]map[K]V)
 	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
 	return ptr
 }
 
-func SliceMapVar[K KeyTypes, V ElemTypes](fs *FlagSet, ptr *[]map[K]V, short byte, long string, dft []map[K]V, desc string, seperator ...string) {
+func SliceMapVar[K KeyTypes, V ElemTypes](fs *FlagSet, ptr *[]map[K]V, short rune, long string, dft []map[K]V, desc string, seperator ...string) {
 	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
 }
 
-func MapSlice[K KeyTypes, V ElemTypes](fs *FlagSet, short byte, long string, dft map[K][]V, desc string, seperator ...string) *map[K][]V {
+func MapSlice[K KeyTypes, V ElemTypes](fs *FlagSet, short rune, long string, dft map[K][]V, desc string, seperator ...string) *map[K][]V {
 	ptr := new(map[K][]V)
 	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
 	return ptr
 }
 
-func MapSliceVar[K KeyTypes, V ElemTypes](fs *FlagSet, ptr *map[K][]V, short byte, long string, dft map[K][]V, desc string, seperator ...string) {
+func MapSliceVar[K KeyTypes, V ElemTypes](fs *FlagSet, ptr *map[K][]V, short rune, long string, dft map[K][]V, desc string, seperator ...string) {
+	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
+}
+
+// NestedMap：两级map参数，如"--limits svc1.cpu:2,svc1.mem:1G"。
+// seperator依次为元素分隔符（默认","）、key/value分隔符（默认":"）、外层/内层key分隔符（默认"."）。
+func NestedMap[K KeyTypes, V ElemTypes](fs *FlagSet, short rune, long string, dft map[K]map[K]V, desc string, seperator ...string) *map[K]map[K]V {
+	ptr := new(map[K]map[K]V)
+	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
+	return ptr
+}
+
+func NestedMapVar[K KeyTypes, V ElemTypes](fs *FlagSet, ptr *map[K]map[K]V, short rune, long string, dft map[K]map[K]V, desc string, seperator ...string) {
 	fs.AnyVar(ptr, short, long, dft, desc, seperator...)
 }
 
@@ -601,6 +1429,17 @@ type arguments struct {
 	args  []string
 	idx   int
 	align bool
+
+	expanded map[string]bool // 已展开的别名，防止循环展开
+
+	printConfig  bool // 是否出现了--print-config
+	printChanged bool // 是否出现了--print-changed
+	last         bool // 是否出现了--last
+	exportEnv    bool // 是否出现了--export-env
+
+	positional []string       // 当前命令的位置参数
+	leftover   bool           // 未识别的子命令作为剩余参数，见FlagSet.Parse
+	repeats    map[*param]int // 各参数出现次数，见Limits.MaxRepeats
 }
 
 func newArgs(args ...string) *arguments {
@@ -615,6 +1454,13 @@ func (s *arguments) end() bool {
 	return s.idx >= len(s.args)
 }
 
+func (s *arguments) peek() string {
+	if s.end() {
+		return ""
+	}
+	return s.args[s.idx]
+}
+
 func (s *arguments) next() string {
 	if s.end() {
 		return ""
@@ -624,21 +1470,127 @@ func (s *arguments) next() string {
 	return s.args[i]
 }
 
-func (fs *FlagSet) parse(args []string) (*FlagSet, error) {
-	return fs._parse(newArgs(args...))
-}
+// setDft：为命令行中未给出的参数赋值，优先级依次为last（见RememberLast）、环境变量、配置文件、默认值。
+func (fs *FlagSet) setDft(last map[string]any) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	params := fs.allParams()
+	state := make(map[*param]int, len(params))
+
+	var visit func(p *param) error
+	visit = func(p *param) error {
+		switch state[p] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%v: default value of %v: circular dependency", fs.fullName(), p.name())
+		}
+		state[p] = visiting
+		for _, dep := range p.deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[p] = visited
 
-func (fs *FlagSet) setDft() {
-	for _, p := range fs.params {
-		if !p.parsed && p.dft != nil {
+		if p.parsed {
+			return nil
+		}
+		if val, ok := last[p.configKey()]; ok && !p.secret {
+			option := "--last:" + p.configKey()
+			reflect.ValueOf(p.ptr).Elem().SetZero()
+			if err := fs.parseAny(p, val, option); err != nil {
+				return err
+			}
+			p.source = SourceLast
+			fs.flagParsed(option, p)
+			return nil
+		}
+		if p.env != "" {
+			if val, ok := fs.getenv(p.env); ok {
+				err := fs._parseValue(newArg(val), "$"+p.env, p)
+				if err != nil {
+					return err
+				}
+				p.source = SourceEnv
+				fs.flagParsed("$"+p.env, p)
+				return nil
+			}
+		}
+		if e, ok := fs.root().configVals[p.configKey()]; ok {
+			option := e.file + ":" + p.configKey()
+			reflect.ValueOf(p.ptr).Elem().SetZero()
+			if err := fs.parseAny(p, e.val, option); err != nil {
+				return err
+			}
+			p.source = SourceConfig
+			fs.flagParsed(option, p)
+			return nil
+		}
+		p.source = SourceDefault
+		switch {
+		case p.setDft != nil:
+			p.setDft(p.ptr)
+		case p.dftFn.IsValid():
+			reflect.ValueOf(p.ptr).Elem().Set(p.dftFn.Call(nil)[0])
+		case p.dft != nil:
 			reflect.ValueOf(p.ptr).Elem().Set(reflect.ValueOf(p.dft))
+		default:
+			return nil
 		}
+		if fs.tracing() {
+			fs.tracef("default %v = %v", p.name(), p.traceValue())
+		}
+		return nil
+	}
+
+	for _, p := range params {
+		if err := visit(p); err != nil {
+			return err
+		}
+	}
+	for _, p := range fs.allArgs() {
+		if err := visit(p); err != nil {
+			return err
+		}
+	}
+	fs.defaultsApplied()
+	return nil
+}
+
+// isSlice：是否为按元素解析的slice参数，net.HardwareAddr等整体解析的类型除外。
+func (p *param) isSlice() bool {
+	return p.kind == reflect.Slice && p.rtyp != typHardwareAddr
+}
+
+// name：参数名称，用于错误信息。
+func (p *param) name() string {
+	if p.arg != "" {
+		return p.arg
+	}
+	if p.long != "" {
+		return "--" + p.long
 	}
+	return "-" + p.short
 }
 
 func (fs *FlagSet) _parse(args *arguments) (*FlagSet, error) {
 	for !args.end() {
 		arg := args.next()
+		if fs.tracing() {
+			fs.tracef("token %q", arg)
+		}
+
+		if arg == "--" {
+			for !args.end() {
+				if err := fs._parseArg(args, args.next()); err != nil {
+					return fs, err
+				}
+			}
+			break
+		}
 
 		if strings.HasPrefix(arg, "--") {
 			if err := fs._parseLong(args, arg); err != nil {
@@ -654,117 +1606,403 @@ func (fs *FlagSet) _parse(args *arguments) (*FlagSet, error) {
 			continue
 		}
 
-		fs.setDft()
+		if p := fs.nextArg(arg); p != nil {
+			if err := fs._parsePosArg(args, arg, p); err != nil {
+				return fs, err
+			}
+			continue
+		}
+
+		if err := fs.setDft(nil); err != nil {
+			return fs, err
+		}
 		return fs._parseSubcmd(args, arg)
 	}
 
-	fs.setDft()
+	var last map[string]any
+	if args.last {
+		var err error
+		if last, err = fs.lastValues(); err != nil {
+			return fs, err
+		}
+	}
+	if err := fs.setDft(last); err != nil {
+		return fs, err
+	}
+	if err := fs.checkArgs(args.positional); err != nil {
+		return fs, err
+	}
+	fs.commandResolved()
 	return fs, nil
 }
 
 func (fs *FlagSet) _parseSubcmd(args *arguments, arg string) (*FlagSet, error) {
-	var cmd *FlagSet
-	for _, c := range fs.cmds {
-		if c.name == arg {
-			cmd = c
-			break
-		}
-	}
+	cmd := fs.subcmd(arg)
 	if cmd == nil {
+		if exp, ok := fs.aliases[arg]; ok {
+			if err := args.expand(arg, exp); err != nil {
+				return fs, fmt.Errorf("%v: %w", fs.name, err)
+			}
+			fs.tracef("alias %v = %q", arg, exp)
+			return fs._parse(args)
+		}
+		if plugin := fs.lookPlugin(args, arg); plugin != nil {
+			fs.tracef("plugin %v", plugin.plugin)
+			return plugin, nil
+		}
 		if arg == "help" {
 			return fs, ErrHelp
 		}
-		return fs, fmt.Errorf("%v: unknown sub command: %v", fs.name, arg)
+		c, err := fs.unknownCommand(arg)
+		if err != nil {
+			return fs, err
+		}
+		if c != nil {
+			args.positional = nil
+			fs.tracef("resolved sub command %v", arg)
+			return c._parse(args)
+		}
+		if args.leftover {
+			args.positional = append(args.positional, arg)
+			return fs._parse(args)
+		}
+		return fs, fmt.Errorf("%v: %w: %v", fs.name, ErrUnknownCommand, arg)
 	}
+	args.positional = nil
+	fs.tracef("sub command %v", arg)
 	return cmd._parse(args)
 }
 
 func (fs *FlagSet) _parseShort(args *arguments, arg string) error {
-	var param *param
-	for _, p := range fs.params {
-		if p.short != "" && "-"+p.short == arg {
-			param = p
-			break
-		}
-	}
+	param := fs.index().short[arg[1:]]
 	if param == nil {
 		if arg == "-h" {
 			return ErrHelp
 		}
-		return fmt.Errorf("%v: unknown option: %v", fs.name, arg)
+		if utf8.RuneCountInString(arg) > 2 && fs.root().singleDash {
+			return fs._parseLong(args, "-"+arg)
+		}
+		return fs.unknownFlag(args, arg)
+	}
+	if err := fs.checkDup(args, arg, param); err != nil {
+		return err
 	}
-	return fs._parseParam(args, arg, param)
+	return fs._parseOption(args, arg, param)
 }
 
 func (fs *FlagSet) _parseLong(args *arguments, arg string) error {
-	var param *param
-	for _, p := range fs.params {
-		if p.long != "" {
-			if "--"+p.long == arg {
-				param = p
-				break
-			}
-			if strings.HasPrefix(arg, "--"+p.long+"=") {
-				param = p
-				break
-			}
+	if root := fs.root(); root.normalize != nil || root.autoAlias {
+		name, val, hasVal := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		arg = "--" + fs.normalizeName(name)
+		if hasVal {
+			arg += "=" + val
 		}
 	}
+
+	name, val, hasVal := strings.Cut(arg[2:], "=")
+	param := fs.index().long[name]
 	if param == nil {
+		if p := fs.negated(arg); p != nil {
+			if err := fs.checkDup(args, arg, p); err != nil {
+				return err
+			}
+			return fs._parseParam(newArg("false"), arg, p)
+		}
 		if arg == "--help" {
 			return ErrHelp
 		}
-		return fmt.Errorf("%v: unknown option: %v", fs.name, arg)
+		if arg == "--print-config" && fs.root().printConfig {
+			args.printConfig = true
+			return nil
+		}
+		if arg == "--print-changed" && fs.root().printChanged {
+			args.printChanged = true
+			return nil
+		}
+		if arg == "--export-env" && fs.root().exportEnv {
+			args.exportEnv = true
+			return nil
+		}
+		if arg == "--last" && fs.root().rememberLast {
+			args.last = true
+			return nil
+		}
+		if fs.root().abbrev {
+			full, err := fs.unabbrev(arg)
+			if err != nil {
+				return err
+			}
+			if full != "" {
+				return fs._parseLong(args, full)
+			}
+		}
+		return fs.unknownFlag(args, arg)
+	}
+	if err := fs.checkDup(args, arg, param); err != nil {
+		return err
 	}
 
-	if strings.HasPrefix(arg, "--"+param.long+"=") {
-		val := strings.TrimPrefix(arg, "--"+param.long+"=")
+	if hasVal {
 		return fs._parseParam(newArg(val), arg, param)
 	}
-	return fs._parseParam(args, arg, param)
+	return fs._parseOption(args, arg, param)
+}
+
+// SingleDashLong：兼容标准库flag的参数形式，长参数也可以"-"开头，如"-verbose"、"-name=value"，
+// 便于从标准库flag迁移。短参数优先，如"-v"仍为短参数。
+func (fs *FlagSet) SingleDashLong() *FlagSet {
+	fs.unsealed()
+	fs.root().singleDash = true
+	return fs
+}
+
+// SetNormalizeFunc：设置长参数名称规范化函数，类似pflag的SetNormalizeFunc。
+// 注册和解析时长参数名称都会先经过fn规范化，如将"_"替换为"-"后，"--my_flag"和"--my-flag"为同一参数。
+// 已注册的长参数也会被重新规范化，规范化后重名时panic。fn应满足fn(fn(x)) == fn(x)。
+func (fs *FlagSet) SetNormalizeFunc(fn func(name string) string) *FlagSet {
+	fs.unsealed()
+	root := fs.root()
+	root.normalize = fn
+	root.renormalize(make(map[*param]bool))
+	return fs
+}
+
+func (fs *FlagSet) renormalize(seen map[*param]bool) {
+	fs.idx = nil
+	fs.touch()
+	params := fs.allParams()
+	longs := make(map[string]bool, len(params))
+	for _, p := range params {
+		if !seen[p] && p.long != "" {
+			p.long = fs.normalizeName(p.long)
+			seen[p] = true
+		}
+		if p.long == "" {
+			continue
+		}
+		if longs[p.long] {
+			panic(fmt.Errorf("flags: duplicated long option: --%v", p.long))
+		}
+		longs[p.long] = true
+	}
+	for _, cmd := range fs.cmds {
+		cmd.renormalize(seen)
+	}
+}
+
+// AutoAlias：长参数名称中"-"和"_"视为同一分隔符，如注册了"--my-flag"时，"--my_flag"同样有效，反之亦然。
+// 长参数统一以"-"分隔展示在Usage中。可与SetNormalizeFunc同时使用，先替换分隔符再调用规范化函数。
+func (fs *FlagSet) AutoAlias() *FlagSet {
+	fs.unsealed()
+	root := fs.root()
+	root.autoAlias = true
+	root.renormalize(make(map[*param]bool))
+	return fs
+}
+
+func (fs *FlagSet) normalizeName(name string) string {
+	root := fs.root()
+	if root.autoAlias {
+		name = strings.ReplaceAll(name, "_", "-")
+	}
+	if root.normalize != nil && name != "" {
+		name = root.normalize(name)
+	}
+	return name
+}
+
+// AllowAbbrev：允许GNU风格的长参数缩写，如唯一以"verb"开头的长参数为"--verbose"时，"--verb"等同于"--verbose"。
+// 前缀匹配多个长参数时报错"ambiguous option"。完整参数名优先，如同时注册了"--in"和"--int"，"--in"不会有歧义。
+func (fs *FlagSet) AllowAbbrev() *FlagSet {
+	fs.unsealed()
+	fs.root().abbrev = true
+	return fs
+}
+
+// unabbrev：将缩写的长参数展开为完整形式，没有匹配的长参数时返回空字符串。
+func (fs *FlagSet) unabbrev(arg string) (string, error) {
+	prefix, val, hasVal := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+	if prefix == "" {
+		return "", nil
+	}
+	var matched []string
+	fs.eachParam(func(p *param) bool {
+		if strings.HasPrefix(p.long, prefix) {
+			matched = append(matched, "--"+p.long)
+		}
+		return true
+	})
+	switch len(matched) {
+	case 0:
+		return "", nil
+	case 1:
+		if hasVal {
+			return matched[0] + "=" + val, nil
+		}
+		return matched[0], nil
+	}
+	return "", fmt.Errorf("%v: ambiguous option: %v (%v)", fs.name, arg, strings.Join(matched, ", "))
+}
+
+// StrictDuplicates：开启严格模式，标量参数在命令行中重复出现时报错（如"-i 1 -i 2"），而不是以最后一个为准。
+// slice和map参数不受影响。
+func (fs *FlagSet) StrictDuplicates() *FlagSet {
+	fs.unsealed()
+	fs.root().strictDup = true
+	return fs
+}
+
+func (fs *FlagSet) checkDup(args *arguments, arg string, p *param) error {
+	if err := fs.checkRepeats(args, arg, p); err != nil {
+		return err
+	}
+	if !fs.root().strictDup || p.source != SourceFlag {
+		return nil
+	}
+	if p.kind == reflect.Map || p.isSlice() {
+		return nil
+	}
+	return fmt.Errorf("%v: duplicate option: %v", fs.name, arg)
+}
+
+// _parseOption：解析以"-x"或"--xx"形式给出的参数，参数值为后续参数。
+// 设置了NoOptDefault的参数，后续参数不是参数值时，使用NoOptDefault的值；
+// 设置了Greedy的slice参数，会连续消耗后续参数值，直到遇到下一个参数或子命令。
+func (fs *FlagSet) _parseOption(args *arguments, arg string, p *param) error {
+	if p.deprecated != nil {
+		fs.notice(arg, "option is deprecated%v", deprecatedHint(*p.deprecated))
+	}
+	if p.noOpt != nil && !fs.hasValue(args) {
+		return fs._parseParam(newArg(*p.noOpt), arg, p)
+	}
+	if err := fs._parseParam(args, arg, p); err != nil {
+		return err
+	}
+	for p.greedy && fs.hasValue(args) {
+		if err := fs._parseSlice(args, arg, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasValue：下一个参数是否可作为参数值，即不是参数也不是子命令。
+func (fs *FlagSet) hasValue(args *arguments) bool {
+	if args.end() {
+		return false
+	}
+	next := args.peek()
+	if strings.HasPrefix(next, "-") {
+		return false
+	}
+	return fs.subcmd(next) == nil
 }
 
 var (
 	typDuration = reflect.TypeOf(time.Duration(0))
 	typDateTime = reflect.TypeOf(time.Time{})
+	typLocation = reflect.TypeOf((*time.Location)(nil))
+
+	typHardwareAddr = reflect.TypeOf(net.HardwareAddr(nil))
+	typFileMode     = reflect.TypeOf(os.FileMode(0))
+	typLogLevel     = reflect.TypeOf(slog.Level(0))
+	typTemplate     = reflect.TypeOf((*template.Template)(nil))
 )
 
 func (fs *FlagSet) _parseParam(args *arguments, arg string, p *param) error {
+	if err := fs._parseValue(args, arg, p); err != nil {
+		return err
+	}
+	fs.flagParsed(arg, p)
+	return nil
+}
+
+func (fs *FlagSet) _parseValue(args *arguments, arg string, p *param) error {
+	if p.source != SourceFlag && (p.isSlice() || p.kind == reflect.Map) {
+		// 解析子命令前父命令已为继承的参数赋了默认值、环境变量、配置或--last的值，
+		// 命令行中首次出现时替换这些值，而不是在其后追加
+		reflect.ValueOf(p.ptr).Elem().SetZero()
+	}
 	p.parsed = true
+	p.source = SourceFlag
 
-	typ := reflect.TypeOf(p.ptr).Elem()
-	switch typ {
+	if p.fromFile || p.fromStdin {
+		var err error
+		if args, err = fs._readValueRef(args, arg, p); err != nil {
+			return err
+		}
+	}
+
+	if p.expandEnv {
+		args = fs._expandEnv(args)
+	}
+
+	if p.set != nil && !fs.lenientOverflow(p) {
+		return fs._parseSet(args, arg, p)
+	}
+
+	switch p.rtyp {
 	case typDuration:
 		return fs._parseDuration(args, arg, p)
 	case typDateTime:
 		return fs._parseDateTime(args, arg, p)
+	case typHardwareAddr:
+		return fs._parseMAC(args, arg, p)
+	case typFileMode:
+		return fs._parseFileMode(args, arg, p)
+	case typLogLevel:
+		return fs._parseLogLevel(args, arg, p)
+	}
+
+	switch p.kind {
 	default:
-		switch typ.Kind() {
-		default:
-			return fs._parseParamErr(arg, fmt.Errorf("unsupported type %v", p.typ))
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			return fs._parseInts(args, arg, p)
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			return fs._parseUints(args, arg, p)
-		case reflect.Float32:
-			return fs._parseFloat32(args, arg, p)
-		case reflect.Float64:
-			return fs._parseFloat64(args, arg, p)
-		case reflect.Bool:
-			return fs._parseBool(args, arg, p)
-		case reflect.String:
-			return fs._parseString(args, arg, p)
-		case reflect.Slice:
-			if args.align {
-				return fs._parseSliceAlign(args, arg, p)
-			}
-			return fs._parseSlice(args, arg, p)
-		case reflect.Map:
-			return fs._parseMap(args, arg, p)
+		return fs._parseParamErr(arg, fmt.Errorf("unsupported type %v", p.typ))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fs._parseInts(args, arg, p)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fs._parseUints(args, arg, p)
+	case reflect.Float32:
+		return fs._parseFloat32(args, arg, p)
+	case reflect.Float64:
+		return fs._parseFloat64(args, arg, p)
+	case reflect.Bool:
+		return fs._parseBool(args, arg, p)
+	case reflect.String:
+		return fs._parseString(args, arg, p)
+	case reflect.Slice:
+		empty := args.align && args.peek() == ""
+		if empty || p.mode == SliceReplace {
+			reflect.ValueOf(p.ptr).Elem().SetZero()
+		}
+		if empty {
+			args.next()
+			return nil
+		}
+		if args.align {
+			return fs._parseSliceAlign(args, arg, p)
 		}
+		return fs._parseSlice(args, arg, p)
+	case reflect.Map:
+		return fs._parseMap(args, arg, p)
+	case reflect.Struct:
+		return fs._parseJSON(args, arg, p)
 	}
 }
 
+// _parseSet：通过param.set解析标量参数，不经过reflect。
+func (fs *FlagSet) _parseSet(args *arguments, arg string, p *param) error {
+	if p.kind == reflect.Bool && !args.align {
+		return p.set(p.ptr, "true")
+	}
+	if args.end() {
+		return fs._parseParamErr(arg, ErrNoInputValue)
+	}
+	if err := p.set(p.ptr, args.next()); err != nil {
+		return fs._parseParamErr(arg, err)
+	}
+	return nil
+}
+
 func (fs *FlagSet) _parseParamErr(arg string, err error) error {
 	return fmt.Errorf("%v: parse option %v: %w", fs.fullName(), arg, err)
 }
@@ -774,7 +2012,7 @@ func (fs *FlagSet) _parseDuration(args *arguments, arg string, p *param) error {
 		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
 
-	dur, err := time.ParseDuration(args.next())
+	dur, err := p.parseDuration(args.next())
 	if err != nil {
 		return fs._parseParamErr(arg, err)
 	}
@@ -787,7 +2025,7 @@ func (fs *FlagSet) _parseDateTime(args *arguments, arg string, p *param) error {
 		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
 
-	t, err := time.ParseInLocation(DateTime, args.next(), time.Local)
+	t, err := fs.parseDateTime(args.next())
 	if err != nil {
 		return fs._parseParamErr(arg, err)
 	}
@@ -795,40 +2033,63 @@ func (fs *FlagSet) _parseDateTime(args *arguments, arg string, p *param) error {
 	return nil
 }
 
-func (fs *FlagSet) _parseInts(args *arguments, arg string, p *param) error {
+func (fs *FlagSet) _parseMAC(args *arguments, arg string, p *param) error {
 	if args.end() {
 		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
 
-	i, err := strconv.ParseInt(args.next(), 10, 64)
+	mac, err := net.ParseMAC(args.next())
 	if err != nil {
 		return fs._parseParamErr(arg, err)
 	}
+	*p.ptr.(*net.HardwareAddr) = mac
+	return nil
+}
+
+func (fs *FlagSet) _parseFileMode(args *arguments, arg string, p *param) error {
+	if args.end() {
+		return fs._parseParamErr(arg, ErrNoInputValue)
+	}
 
-	val := reflect.ValueOf(p.ptr).Elem()
-	val.SetInt(i)
-	if val.Int() != i {
-		return fs._parseParamErr(arg, fmt.Errorf("cannot set %v to an %v, overflowed", i, p.typ))
+	mode, err := parseFileMode(args.next())
+	if err != nil {
+		return fs._parseParamErr(arg, err)
 	}
+	*p.ptr.(*os.FileMode) = mode
 	return nil
 }
 
-func (fs *FlagSet) _parseUints(args *arguments, arg string, p *param) error {
+func (fs *FlagSet) _parseLogLevel(args *arguments, arg string, p *param) error {
 	if args.end() {
 		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
 
-	i, err := strconv.ParseUint(args.next(), 10, 64)
+	level, err := parseLogLevel(args.next())
 	if err != nil {
 		return fs._parseParamErr(arg, err)
 	}
+	*p.ptr.(*slog.Level) = level
+	return nil
+}
 
-	val := reflect.ValueOf(p.ptr).Elem()
-	val.SetUint(i)
-	if val.Uint() != i {
-		return fs._parseParamErr(arg, fmt.Errorf("cannot set %v to an %v, overflowed", i, p.typ))
+func (fs *FlagSet) _parseInts(args *arguments, arg string, p *param) error {
+	if args.end() {
+		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
-	return nil
+
+	s := args.next()
+	i, err := p.parseInt(s)
+	return fs.setInt(reflect.ValueOf(p.ptr).Elem(), arg, p, s, i, err)
+}
+
+func (fs *FlagSet) _parseUints(args *arguments, arg string, p *param) error {
+	if args.end() {
+		return fs._parseParamErr(arg, ErrNoInputValue)
+	}
+
+	s := args.next()
+	i, err := p.parseUint(s)
+	return fs.setUint(reflect.ValueOf(p.ptr).Elem(), arg, p, s, i, err)
 }
 
 func (fs *FlagSet) _parseFloat32(args *arguments, arg string, p *param) error {
@@ -836,7 +2097,7 @@ func (fs *FlagSet) _parseFloat32(args *arguments, arg string, p *param) error {
 		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
 
-	f, err := strconv.ParseFloat(args.next(), 32)
+	f, err := p.parseFloat(args.next(), 32)
 	if err != nil {
 		return fs._parseParamErr(arg, err)
 	}
@@ -849,7 +2110,7 @@ func (fs *FlagSet) _parseFloat64(args *arguments, arg string, p *param) error {
 		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
 
-	f, err := strconv.ParseFloat(args.next(), 64)
+	f, err := p.parseFloat(args.next(), 64)
 	if err != nil {
 		return fs._parseParamErr(arg, err)
 	}
@@ -863,17 +2124,12 @@ func (fs *FlagSet) _parseBool(args *arguments, arg string, p *param) error {
 		return nil
 	}
 
-	s := args.next()
-	if s == "true" {
-		*p.ptr.(*bool) = true
-		return nil
-	}
-	if s == "false" {
-		*p.ptr.(*bool) = false
-		return nil
+	b, err := parseBool(args.next())
+	if err != nil {
+		return fs._parseParamErr(arg, err)
 	}
-
-	return fs._parseParamErr(arg, fmt.Errorf("invalid bool value: %q", s))
+	*p.ptr.(*bool) = b
+	return nil
 }
 
 func (fs *FlagSet) _parseString(args *arguments, arg string, p *param) error {
@@ -884,28 +2140,34 @@ func (fs *FlagSet) _parseString(args *arguments, arg string, p *param) error {
 	return nil
 }
 
-func (fs *FlagSet) _parseSlice(args *arguments, arg string, p *param) error {
+// _parseJSON：struct参数以JSON对象形式给出，如"--endpoint '{"host":"a","port":80}'"。
+func (fs *FlagSet) _parseJSON(args *arguments, arg string, p *param) error {
 	if args.end() {
 		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
+	if err := json.Unmarshal([]byte(args.next()), p.ptr); err != nil {
+		return fs._parseParamErr(arg, err)
+	}
+	return nil
+}
 
-	val := reflect.ValueOf(p.ptr).Elem()
-	typ := val.Type().Elem()
-	isPtr := typ.Kind() == reflect.Pointer
-	if isPtr {
-		typ = typ.Elem()
+func (fs *FlagSet) _parseSlice(args *arguments, arg string, p *param) error {
+	if args.end() {
+		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
 
-	bak := p.ptr
-	defer func() { p.ptr = bak }()
+	if err := fs.checkElems(arg, p, 1, "", ""); err != nil {
+		return err
+	}
 
-	ptr := reflect.New(typ)
-	p.ptr = ptr.Interface()
-	err := fs._parseParam(args, arg, p)
+	val := reflect.ValueOf(p.ptr).Elem()
+	ptr := reflect.New(p.elem.rtyp)
+	p.elem.ptr = ptr.Interface()
+	err := fs._parseParam(args, arg, p.elem)
 	if err != nil {
 		return err
 	}
-	if isPtr {
+	if p.elemPtr {
 		val.Set(reflect.Append(val, ptr))
 	} else {
 		val.Set(reflect.Append(val, ptr.Elem()))
@@ -918,36 +2180,50 @@ func (fs *FlagSet) _parseSliceAlign(args *arguments, arg string, p *param) error
 		return fs._parseParamErr(arg, ErrNoInputValue)
 	}
 
-	val := reflect.ValueOf(p.ptr).Elem()
-	typ := val.Type().Elem()
-	if typ.Kind() == reflect.Map {
+	if p.elem.kind == reflect.Map || p.elem.kind == reflect.Struct {
 		return fs._parseSlice(newArgs(args.next()), arg, p)
 	}
 
-	isPtr := typ.Kind() == reflect.Pointer
-	if isPtr {
-		typ = typ.Elem()
+	s := args.next()
+	if err := fs.checkElems(arg, p, 0, s, p.sep1); err != nil {
+		return err
 	}
-
-	bak := p.ptr
-	defer func() { p.ptr = bak }()
-
-	for _, elem := range strings.Split(args.next(), p.sep1) {
-		ptr := reflect.New(typ)
-		p.ptr = ptr.Interface()
-		err := fs._parseParam(newArg(elem), arg, p)
+	val := reflect.ValueOf(p.ptr).Elem()
+	val.Grow(strings.Count(s, p.sep1) + 1)
+	var sc scratch
+	var ptr reflect.Value
+	for rest, more := s, true; more; {
+		var elem string
+		elem, rest, more = cutEscaped(rest, p.sep1)
+		if p.elemPtr || !ptr.IsValid() {
+			ptr = reflect.New(p.elem.rtyp)
+			p.elem.ptr = ptr.Interface()
+		} else {
+			ptr.Elem().SetZero()
+		}
+		err := fs._parseParam(sc.of(unescape(elem, p.sep1), true), arg, p.elem)
 		if err != nil {
 			return err
 		}
-		if isPtr {
-			val.Set(reflect.Append(val, ptr))
+		if p.elemPtr {
+			appendValue(val, ptr)
 		} else {
-			val.Set(reflect.Append(val, ptr.Elem()))
+			appendValue(val, ptr.Elem())
 		}
 	}
 	return nil
 }
 
+// appendValue：同val.Set(reflect.Append(val, x))，容量足够时不分配内存。
+func appendValue(val, x reflect.Value) {
+	n := val.Len()
+	if n == val.Cap() {
+		val.Grow(1)
+	}
+	val.SetLen(n + 1)
+	val.Index(n).Set(x)
+}
+
 func (fs *FlagSet) _parseMap(args *arguments, arg string, p *param) error {
 	if args.end() {
 		return fs._parseParamErr(arg, ErrNoInputValue)
@@ -956,53 +2232,119 @@ func (fs *FlagSet) _parseMap(args *arguments, arg string, p *param) error {
 	if s == "" {
 		return nil
 	}
+	if err := fs.checkElems(arg, p, 0, s, p.sep1); err != nil {
+		return err
+	}
 
 	val := reflect.ValueOf(p.ptr).Elem()
-	typ := val.Type()
-	kt := typ.Key()
-	vt := typ.Elem()
-
-	for _, pair := range strings.Split(s, p.sep1) {
-		kv := strings.Split(pair, p.sep2)
-		if len(kv) != 2 {
+	var (
+		sc   scratch
+		k, v reflect.Value
+	)
+	for rest, more := s, true; more; {
+		var pair string
+		pair, rest, more = cutEscaped(rest, p.sep1)
+		key, value, ok := cutEscaped(pair, p.sep2)
+		if _, _, extra := cutEscaped(value, p.sep2); !ok || extra {
 			return fs._parseParamErr(arg,
-				fmt.Errorf("parse key/value: split %q by %q: found %v part(s)", pair, p.sep2, len(kv)),
+				fmt.Errorf("parse key/value: split %q by %q: found %v part(s)", pair, p.sep2, len(splitEscaped(pair, p.sep2))),
 			)
 		}
 
-		k := reflect.New(kt)
-		v := reflect.New(vt)
+		if p.elem.kind == reflect.Map {
+			if err := fs._parseNestedMap(val, arg, p, key, value, &sc); err != nil {
+				return err
+			}
+			continue
+		}
 
-		err := fs._parseParam(
-			&arguments{args: []string{kv[0]}},
-			arg,
-			&param{typ: kt.String(), ptr: k.Interface()},
-		)
+		// key、value在SetMapIndex时被复制，可在各元素间复用
+		if !k.IsValid() {
+			k = reflect.New(p.key.rtyp)
+			v = reflect.New(p.elem.rtyp)
+		} else {
+			k.Elem().SetZero()
+			v.Elem().SetZero()
+		}
+
+		p.key.ptr = k.Interface()
+		err := fs._parseParam(sc.of(unescape(key, p.sep1, p.sep2), false), arg, p.key)
 		if err != nil {
 			return err
 		}
 
-		err = fs._parseParam(
-			&arguments{args: []string{kv[1]}},
-			arg,
-			&param{typ: vt.String(), ptr: v.Interface()},
-		)
+		p.elem.ptr = v.Interface()
+		err = fs._parseParam(sc.of(unescape(value, p.sep1, p.sep2), false), arg, p.elem)
 		if err != nil {
 			return err
 		}
 
 		if val.IsNil() {
-			val.Set(reflect.MakeMap(typ))
+			val.Set(reflect.MakeMap(p.rtyp))
 		}
-		if vt.Kind() == reflect.Slice {
-			if ori := val.MapIndex(k.Elem()); ori.IsValid() {
-				val.SetMapIndex(k.Elem(), reflect.AppendSlice(ori, v.Elem()))
-			} else {
-				val.SetMapIndex(k.Elem(), v.Elem())
-			}
-		} else {
+		ori := val.MapIndex(k.Elem())
+		switch {
+		case !ori.IsValid():
+			val.SetMapIndex(k.Elem(), v.Elem())
+		case p.elem.isSlice():
+			val.SetMapIndex(k.Elem(), reflect.AppendSlice(ori, v.Elem()))
+		case p.dup == DupError:
+			return fs._parseParamErr(arg, fmt.Errorf("duplicated key: %v", key))
+		case p.dup == DupOverwrite:
+			fs.warn(arg, "duplicated key %v overwritten", key)
 			val.SetMapIndex(k.Elem(), v.Elem())
 		}
 	}
 	return nil
 }
+
+// _parseNestedMap：解析两级map的一个key/value对，keys由外层key和内层key以sep3连接。
+func (fs *FlagSet) _parseNestedMap(val reflect.Value, arg string, p *param, keys, value string, sc *scratch) error {
+	outer, innerKey, ok := cutEscaped(keys, p.sep3)
+	if _, _, extra := cutEscaped(innerKey, p.sep3); !ok || extra {
+		return fs._parseParamErr(arg,
+			fmt.Errorf("parse nested key: split %q by %q: found %v part(s)", keys, p.sep3, len(splitEscaped(keys, p.sep3))),
+		)
+	}
+
+	inner := p.elem
+	k := reflect.New(p.key.rtyp)
+	ik := reflect.New(inner.key.rtyp)
+	v := reflect.New(inner.elem.rtyp)
+
+	p.key.ptr = k.Interface()
+	err := fs._parseParam(sc.of(unescape(outer, p.sep1, p.sep2, p.sep3), false), arg, p.key)
+	if err != nil {
+		return err
+	}
+	inner.key.ptr = ik.Interface()
+	err = fs._parseParam(sc.of(unescape(innerKey, p.sep1, p.sep2, p.sep3), false), arg, inner.key)
+	if err != nil {
+		return err
+	}
+	inner.elem.ptr = v.Interface()
+	err = fs._parseParam(sc.of(unescape(value, p.sep1, p.sep2, p.sep3), false), arg, inner.elem)
+	if err != nil {
+		return err
+	}
+
+	if val.IsNil() {
+		val.Set(reflect.MakeMap(p.rtyp))
+	}
+	m := val.MapIndex(k.Elem())
+	if !m.IsValid() || m.IsNil() {
+		m = reflect.MakeMap(inner.rtyp)
+		val.SetMapIndex(k.Elem(), m)
+	}
+	if m.MapIndex(ik.Elem()).IsValid() {
+		switch p.dup {
+		case DupError:
+			return fs._parseParamErr(arg, fmt.Errorf("duplicated key: %v", keys))
+		case DupKeepFirst:
+			return nil
+		}
+		fs.warn(arg, "duplicated key %v overwritten", keys)
+	}
+	m.SetMapIndex(ik.Elem(), v.Elem())
+	return nil
+}
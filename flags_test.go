@@ -2,7 +2,13 @@ package flags
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
 	"testing"
+	"text/template"
 	"time"
 )
 
@@ -10,13 +16,16 @@ func TestHandle(t *testing.T) {
 	fs := New("handle", "")
 	var run bool
 	fs.Handle(func(context.Context) { run = true })
-	_, err := fs.Run(context.Background())
+	usage, err := fs.Run(context.Background())
 	if err != nil {
 		t.Fatalf("handle run: %v", err)
 	}
 	if !run {
 		t.Fatal("handle: not run")
 	}
+	if usage != "" {
+		t.Fatalf("handle: usage generated on success: %q", usage)
+	}
 }
 
 func TestUse(t *testing.T) {
@@ -190,6 +199,36 @@ func TestBool(t *testing.T) {
 	}
 }
 
+func TestLenientBool(t *testing.T) {
+	fs := New("bool", "")
+	b := fs.Bool('b', "bool", false, "a bool value")
+	fs.Handle(func(context.Context) {})
+
+	for _, c := range []struct {
+		val string
+		exp bool
+	}{
+		{"1", true}, {"0", false},
+		{"yes", true}, {"No", false},
+		{"ON", true}, {"off", false},
+		{"t", true}, {"F", false},
+		{"True", true}, {"FALSE", false},
+	} {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), "--bool="+c.val)
+		if err != nil {
+			t.Fatalf("bool run %v: %v", c.val, err)
+		}
+		if *b != c.exp {
+			t.Fatalf("bool run %v result: %v", c.val, *b)
+		}
+	}
+
+	if _, err := fs.Run(context.Background(), "--bool=maybe"); err == nil {
+		t.Fatalf("bool run: no err")
+	}
+}
+
 func TestDuration(t *testing.T) {
 	var d time.Duration
 	fs := New("duration", "")
@@ -309,6 +348,46 @@ func sliceEqual[T comparable](s []T, t ...T) bool {
 	return true
 }
 
+func TestRelativeDateTime(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.Local)
+	today := time.Date(2024, 3, 15, 0, 0, 0, 0, time.Local)
+	fs := New("relative", "").SetClock(func() time.Time { return now })
+	since := fs.DateTime('s', "since", time.Time{}, "start time")
+	until := Slice[time.Time](fs, 'u', "until", nil, "end time")
+	fs.Handle(func(context.Context) {})
+
+	cases := []struct {
+		val string
+		exp time.Time
+	}{
+		{"now", now},
+		{"NOW-1h", now.Add(-time.Hour)},
+		{"now+1d", now.Add(24 * time.Hour)},
+		{"today", today},
+		{"today+9h", today.Add(9 * time.Hour)},
+		{"yesterday", today.AddDate(0, 0, -1)},
+		{"tomorrow-1w", today.AddDate(0, 0, 1).Add(-7 * 24 * time.Hour)},
+		{"2024-01-02T15:04:05", time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)},
+	}
+	for _, c := range cases {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), "-s", c.val, "-u", c.val)
+		if err != nil {
+			t.Fatalf("relative run %v: %v", c.val, err)
+		}
+		if !since.Equal(c.exp) || len(*until) != 1 || !(*until)[0].Equal(c.exp) {
+			t.Fatalf("relative run %v result: %v %v", c.val, *since, *until)
+		}
+	}
+
+	for _, val := range []string{"now-", "now-x", "later"} {
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), "-s", val); err == nil {
+			t.Fatalf("relative run %v: no err", val)
+		}
+	}
+}
+
 func TestSliceVar(t *testing.T) {
 	var s []int64
 	fs := New("slice", "")
@@ -536,3 +615,700 @@ func TestMapSlice(t *testing.T) {
 		t.Fatalf("map_slice run: %v", err)
 	}
 }
+
+func BenchmarkScalar(b *testing.B) {
+	fs := New("scalar", "")
+	fs.Int('i', "int", 1, "a number value")
+	fs.Str('s', "str", "x", "a string value")
+	fs.Bool('b', "bool", false, "a bool value")
+	fs.Duration('d', "dur", time.Second, "a duration value")
+	fs.Handle(func(context.Context) {})
+
+	args := []string{"-i", "123", "--str=abc", "-b", "--dur", "3s"}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fs.Run(ctx, args...)
+	}
+}
+
+func BenchmarkMap(b *testing.B) {
+	fs := New("map", "")
+	Map[string, int](fs, 'm', "map", nil, "a map value")
+	fs.Handle(func(context.Context) {})
+
+	args := []string{"--map=a:1,b:2,c:3,d:4"}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fs.Reset()
+		fs.Run(ctx, args...)
+	}
+}
+
+func BenchmarkSlice(b *testing.B) {
+	fs := New("slice", "")
+	Slice[int](fs, 's', "slice", nil, "a slice value")
+	fs.Handle(func(context.Context) {})
+
+	args := []string{"--slice=1,2,3,4,5,6,7,8"}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fs.Reset()
+		fs.Run(ctx, args...)
+	}
+}
+
+func BenchmarkSubcmd(b *testing.B) {
+	fs := New("app", "")
+	for i := 0; i < 500; i++ {
+		fs.Cmd(fmt.Sprintf("cmd%03d", i), "").Handle(func(context.Context) {})
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fs.Run(ctx, "cmd499")
+	}
+}
+
+func TestDuplicatedSubcmd(t *testing.T) {
+	fs := New("app", "")
+	fs.Cmd("build", "")
+	s := fs.Stmt()
+	defer func() {
+		if r := recover(); r == nil || fmt.Sprint(r) != "flags: duplicated subcommand: build" {
+			t.Fatalf("duplicated subcommand: %v", r)
+		}
+	}()
+	s.Cmd("build", "")
+}
+
+func TestLazyDefault(t *testing.T) {
+	var host string
+	var called int
+	fs := New("lazy", "")
+	fs.AnyVar(&host, 'H', "host", func() string {
+		called++
+		return "localhost"
+	}, "host name")
+	fs.Handle(func(context.Context) {})
+
+	// set
+	_, err := fs.Run(context.Background(), "-H", "example.com")
+	if err != nil {
+		t.Fatalf("lazy run: %v", err)
+	}
+	if host != "example.com" || called != 0 {
+		t.Fatalf("lazy run result: %v, called: %v", host, called)
+	}
+
+	// default
+	fs.Reset()
+	_, err = fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("lazy run: %v", err)
+	}
+	if host != "localhost" || called != 1 {
+		t.Fatalf("lazy run result: %v, called: %v", host, called)
+	}
+}
+
+func TestNestedMap(t *testing.T) {
+	var nm map[string]map[string]string
+	fs := New("nested_map", "")
+	NestedMapVar(fs, &nm, 'l', "limits", map[string]map[string]string{"svc": {"cpu": "1"}}, "limits of services", ",", "=")
+
+	// default
+	fs.Handle(func(context.Context) {
+		if len(nm) != 1 || !mapEqual(nm["svc"], map[string]string{"cpu": "1"}) {
+			t.Fatalf("nested_map run result: %v", nm)
+		}
+	})
+	_, err := fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("nested_map run: %v", err)
+	}
+
+	// short & long align
+	nm = nil
+	fs.Handle(func(context.Context) {
+		if len(nm) != 2 ||
+			!mapEqual(nm["svc1"], map[string]string{"cpu": "2", "mem": "1G"}) ||
+			!mapEqual(nm["svc2"], map[string]string{"cpu": "4"}) {
+			t.Fatalf("nested_map run result: %v", nm)
+		}
+	})
+	_, err = fs.Run(context.Background(), "-l", "svc1.cpu=2,svc1.mem=1G", "--limits=svc2.cpu=4")
+	if err != nil {
+		t.Fatalf("nested_map run: %v", err)
+	}
+
+	// invalid key
+	_, err = fs.Run(context.Background(), "-l", "cpu=2")
+	if err == nil {
+		t.Fatalf("nested_map run: no err")
+	}
+}
+
+type endpoint struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestSliceStruct(t *testing.T) {
+	var eps []endpoint
+	fs := New("slice_struct", "")
+	fs.AnyVar(&eps, 'e', "endpoint", []endpoint{{"localhost", 80}}, "endpoints")
+
+	// default
+	fs.Handle(func(context.Context) {
+		if !sliceEqual(eps, endpoint{"localhost", 80}) {
+			t.Fatalf("slice_struct run result: %v", eps)
+		}
+	})
+	_, err := fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("slice_struct run: %v", err)
+	}
+
+	// short & long align
+	eps = nil
+	fs.Handle(func(context.Context) {
+		if !sliceEqual(eps, endpoint{"a", 80}, endpoint{"b", 8080}) {
+			t.Fatalf("slice_struct run result: %v", eps)
+		}
+	})
+	_, err = fs.Run(context.Background(), "-e", `{"host":"a","port":80}`, `--endpoint={"host":"b","port":8080}`)
+	if err != nil {
+		t.Fatalf("slice_struct run: %v", err)
+	}
+
+	// invalid json
+	_, err = fs.Run(context.Background(), "-e", `{"host":`)
+	if err == nil {
+		t.Fatalf("slice_struct run: no err")
+	}
+}
+
+func TestLocation(t *testing.T) {
+	var loc *time.Location
+	fs := New("location", "")
+	fs.LocationVar(&loc, 'z', "tz", time.UTC, "a time zone")
+
+	// default
+	fs.Handle(func(context.Context) {
+		if loc != time.UTC {
+			t.Fatalf("location run result: %v", loc)
+		}
+	})
+	_, err := fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("location run: %v", err)
+	}
+
+	// long
+	fs.Handle(func(context.Context) {
+		if loc.String() != "Local" {
+			t.Fatalf("location run result: %v", loc)
+		}
+	})
+	_, err = fs.Run(context.Background(), "--tz", "Local")
+	if err != nil {
+		t.Fatalf("location run: %v", err)
+	}
+
+	// invalid
+	_, err = fs.Run(context.Background(), "--tz=Nowhere/Nothing")
+	if err == nil {
+		t.Fatalf("location run: no err")
+	}
+}
+
+func TestMAC(t *testing.T) {
+	var mac net.HardwareAddr
+	var macs []net.HardwareAddr
+	fs := New("mac", "")
+	dft, _ := net.ParseMAC("00:00:5e:00:53:01")
+	fs.MACVar(&mac, 'm', "mac", dft, "a mac address")
+	SliceVar(fs, &macs, 's', "macs", nil, "a slice of mac address")
+
+	// default
+	fs.Handle(func(context.Context) {
+		if mac.String() != "00:00:5e:00:53:01" {
+			t.Fatalf("mac run result: %v", mac)
+		}
+	})
+	_, err := fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("mac run: %v", err)
+	}
+
+	// long & slice
+	fs.Handle(func(context.Context) {
+		if mac.String() != "02:00:5e:10:00:00" ||
+			len(macs) != 3 || macs[0].String() != "00:00:00:00:00:01" ||
+			macs[1].String() != "00:00:00:00:00:02" || macs[2].String() != "00:00:00:00:00:03" {
+			t.Fatalf("mac run result: %v %v", mac, macs)
+		}
+	})
+	_, err = fs.Run(context.Background(), "--mac", "02-00-5e-10-00-00",
+		"-s", "00:00:00:00:00:01", "--macs=00:00:00:00:00:02,00:00:00:00:00:03")
+	if err != nil {
+		t.Fatalf("mac run: %v", err)
+	}
+
+	// invalid
+	_, err = fs.Run(context.Background(), "--mac=xx")
+	if err == nil {
+		t.Fatalf("mac run: no err")
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	var mode os.FileMode
+	fs := New("filemode", "")
+	fs.FileModeVar(&mode, 'm', "mode", 0644, "a file mode")
+
+	// default
+	fs.Handle(func(context.Context) {
+		if mode != 0644 {
+			t.Fatalf("filemode run result: %v", mode)
+		}
+	})
+	_, err := fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("filemode run: %v", err)
+	}
+
+	// long
+	fs.Handle(func(context.Context) {
+		if mode != 0755 {
+			t.Fatalf("filemode run result: %v", mode)
+		}
+	})
+	_, err = fs.Run(context.Background(), "--mode", "0755")
+	if err != nil {
+		t.Fatalf("filemode run: %v", err)
+	}
+
+	// invalid
+	_, err = fs.Run(context.Background(), "--mode=0789")
+	if err == nil {
+		t.Fatalf("filemode run: no err")
+	}
+
+	// type bits are not permission bits
+	for _, s := range []string{"010000", "040000000000"} {
+		_, err = fs.Run(context.Background(), "--mode", s)
+		if err == nil || !strings.Contains(err.Error(), "out of range") {
+			t.Fatalf("filemode run %v: %v", s, err)
+		}
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, "--mode filemode (default: 0644)") {
+		t.Fatalf("filemode usage: %v", usage)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	var tmpl *template.Template
+	fs := New("template", "")
+	fs.TemplateVar(&tmpl, 'f', "format", template.Must(template.New("").Parse("{{.}}")), "output format")
+
+	exec := func() string {
+		b := new(strings.Builder)
+		if err := tmpl.Execute(b, "x"); err != nil {
+			t.Fatalf("template execute: %v", err)
+		}
+		return b.String()
+	}
+
+	// default
+	fs.Handle(func(context.Context) {
+		if s := exec(); s != "x" {
+			t.Fatalf("template run result: %v", s)
+		}
+	})
+	_, err := fs.Run(context.Background())
+	if err != nil {
+		t.Fatalf("template run: %v", err)
+	}
+
+	// long
+	fs.Handle(func(context.Context) {
+		if s := exec(); s != "name: x" {
+			t.Fatalf("template run result: %v", s)
+		}
+	})
+	_, err = fs.Run(context.Background(), "--format", "name: {{.}}")
+	if err != nil {
+		t.Fatalf("template run: %v", err)
+	}
+
+	// invalid
+	_, err = fs.Run(context.Background(), "--format={{.Name")
+	if err == nil || !strings.Contains(err.Error(), "format:1:") {
+		t.Fatalf("template run err: %v", err)
+	}
+
+	// every parse compiles a new template: earlier results and define blocks are not affected
+	fs.Handle(func(context.Context) {})
+	if _, err = fs.Run(context.Background(), "-f", `{{define "x"}}a{{end}}{{template "x"}}`); err != nil {
+		t.Fatalf("template run: %v", err)
+	}
+	first := tmpl
+	if _, err = fs.Run(context.Background(), "-f", `{{template "x"}}`); err != nil {
+		t.Fatalf("template run: %v", err)
+	}
+	if first == tmpl || first.Lookup("x") == nil || tmpl.Lookup("x") != nil {
+		t.Fatalf("template shared between runs")
+	}
+	if b := new(strings.Builder); first.Execute(b, nil) != nil || b.String() != "a" {
+		t.Fatalf("first template: %q", b)
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, `--format template (default: "{{.}}")`) {
+		t.Fatalf("template usage: %v", usage)
+	}
+}
+
+func TestStrictDuplicates(t *testing.T) {
+	fs := New("strict", "strict duplicates").StrictDuplicates()
+	i := fs.Int('i', "int", 0, "")
+	fs.Bool('v', "verbose", false, "")
+	fs.Flag("verbose").Negatable()
+	tags := Slice[string](fs, 't', NoLong, nil, "")
+	fs.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "-i", "1", "-t", "a", "-t", "b"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *i != 1 || len(*tags) != 2 {
+		t.Fatalf("int: %v, tags: %v", *i, *tags)
+	}
+
+	for _, args := range [][]string{
+		{"-i", "1", "-i", "2"},
+		{"-i", "1", "--int=2"},
+		{"--verbose", "--no-verbose"},
+	} {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), args...)
+		if err == nil || !strings.Contains(err.Error(), "duplicate option") {
+			t.Fatalf("run %q: %v", args, err)
+		}
+	}
+}
+
+func TestSingleDashLong(t *testing.T) {
+	fs := New("dash", "single dash long").SingleDashLong()
+	name := fs.Str(NoShort, "name", "", "")
+	verbose := fs.Bool('v', "verbose", false, "")
+	n := fs.Int('n', "num", 0, "")
+	fs.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "-name=x", "-verbose", "-num", "3"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *name != "x" || !*verbose || *n != 3 {
+		t.Fatalf("name: %q, verbose: %v, num: %v", *name, *verbose, *n)
+	}
+
+	if _, err := fs.Run(context.Background(), "-help"); !errors.Is(err, ErrHelp) {
+		t.Fatalf("-help: %v", err)
+	}
+
+	fs = New("dash", "no single dash long")
+	fs.Str(NoShort, "name", "", "")
+	if _, err := fs.Run(context.Background(), "-name=x"); err == nil {
+		t.Fatalf("-name=x should fail without SingleDashLong")
+	}
+}
+
+func TestAllowAbbrev(t *testing.T) {
+	fs := New("abbrev", "abbreviation").AllowAbbrev()
+	verbose := fs.Bool(NoShort, "verbose", false, "")
+	fs.Bool(NoShort, "version", false, "")
+	in := fs.Str(NoShort, "in", "", "")
+	n := fs.Int(NoShort, "int", 0, "")
+	fs.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "--verb", "--in", "x", "--i=3"); err == nil ||
+		!strings.Contains(err.Error(), "ambiguous option: --i=3 (--in, --int)") {
+		t.Fatalf("ambiguous --i: %v", err)
+	}
+
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "--verb", "--in", "x", "--int=3"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !*verbose || *in != "x" || *n != 3 {
+		t.Fatalf("verbose: %v, in: %q, int: %v", *verbose, *in, *n)
+	}
+
+	_, err := fs.Run(context.Background(), "--ver")
+	if err == nil || !strings.Contains(err.Error(), "ambiguous option: --ver (--verbose, --version)") {
+		t.Fatalf("ambiguous --ver: %v", err)
+	}
+}
+
+func TestSetNormalizeFunc(t *testing.T) {
+	fs := New("normalize", "normalize func")
+	out := fs.Str(NoShort, "out_file", "", "")
+	fs.SetNormalizeFunc(func(name string) string {
+		b := new(strings.Builder)
+		for i := 0; i < len(name); i++ {
+			switch c := name[i]; {
+			case c == '_':
+				b.WriteByte('-')
+			case 'A' <= c && c <= 'Z':
+				b.WriteByte('-')
+				b.WriteByte(c - 'A' + 'a')
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return b.String()
+	})
+	my := fs.Int(NoShort, "myFlag", 0, "")
+	fs.Handle(func(context.Context) {})
+
+	for _, args := range [][]string{
+		{"--my_flag", "1", "--out-file", "a"},
+		{"--my-flag=2", "--outFile", "b"},
+		{"--myFlag", "3", "--out_file=c"},
+	} {
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), args...); err != nil {
+			t.Fatalf("run %q: %v", args, err)
+		}
+	}
+	if *my != 3 || *out != "c" {
+		t.Fatalf("my flag: %v, out file: %q", *my, *out)
+	}
+	if fs.Flag("my_flag").p.long != "my-flag" {
+		t.Fatalf("normalized long: %v", fs.Flag("my_flag").p.long)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("duplicated normalized option should panic")
+		}
+	}()
+	fs.Int(NoShort, "my_flag", 0, "")
+}
+
+func TestAutoAlias(t *testing.T) {
+	fs := New("alias", "auto alias")
+	out := fs.Str(NoShort, "out_file", "", "")
+	fs.AutoAlias()
+	dry := fs.Bool(NoShort, "dry-run", false, "")
+	fs.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "--out-file", "a", "--dry_run"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *out != "a" || !*dry {
+		t.Fatalf("out file: %q, dry run: %v", *out, *dry)
+	}
+
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "--out_file=b", "--dry-run"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *out != "b" || !*dry {
+		t.Fatalf("out file: %q, dry run: %v", *out, *dry)
+	}
+	if !strings.Contains(fs.Usage(), "--out-file string") {
+		t.Fatalf("usage: %v", fs.Usage())
+	}
+}
+
+func TestRuneShort(t *testing.T) {
+	fs := New("rune", "rune short")
+	name := fs.Str('名', "name", "", "")
+	e := fs.Bool('é', NoLong, false, "")
+	fs.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "-名", "x", "-é"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *name != "x" || !*e {
+		t.Fatalf("name: %q, é: %v", *name, *e)
+	}
+	if !strings.Contains(fs.Usage(), "-名, --name string") {
+		t.Fatalf("usage: %v", fs.Usage())
+	}
+	if ValidShort('-') || ValidShort('☺') {
+		t.Fatalf("symbols should not be valid short options")
+	}
+}
+
+func TestCmdLocal(t *testing.T) {
+	fs := New("local", "local command")
+	config := fs.Str('c', "config", "app.cfg", "")
+	var got string
+	fs.Use(func(ctx context.Context, h Handler) {
+		got = *config
+		h(ctx)
+	})
+	sub := fs.CmdLocal("version", "print version")
+	sub.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "version", "-c", "x.cfg")
+	if err == nil || !strings.Contains(err.Error(), "unknown option: -c") {
+		t.Fatalf("local command with parent option: %v", err)
+	}
+
+	fs.Reset()
+	if _, err = fs.Run(context.Background(), "version"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got != "app.cfg" {
+		t.Fatalf("parent middleware config: %q", got)
+	}
+	if strings.Contains(sub.Usage(), "--config") {
+		t.Fatalf("usage: %v", sub.Usage())
+	}
+}
+
+func TestShadowOption(t *testing.T) {
+	fs := New("shadow", "shadow option")
+	output := fs.Str('o', "output", "out.txt", "output file")
+	fs.Handle(func(context.Context) {})
+	sub := fs.Cmd("sub", "")
+	format := sub.Int('o', "output", 3, "output format")
+	sub.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "sub", "-o", "5"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *format != 5 || *output != "out.txt" {
+		t.Fatalf("sub output: %v, output: %q", *format, *output)
+	}
+	if usage := sub.Usage(); !strings.Contains(usage, "-o, --output int (default: 3)\n    output format") ||
+		strings.Contains(usage, "output file") {
+		t.Fatalf("usage: %v", usage)
+	}
+
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "-o", "x.txt"); err != nil || *output != "x.txt" {
+		t.Fatalf("run: %v, output: %q", err, *output)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("duplicated option in the same command should panic")
+		}
+	}()
+	sub.Bool(NoShort, "output", false, "")
+}
+
+func TestCommandMetadata(t *testing.T) {
+	fs := New("app", "")
+	remote := fs.Cmd("remote", "")
+	add := remote.Cmd("add", "add a remote")
+	Arg[string](add, "name", "", "")
+	Arg[string](add, "url", "", "")
+
+	var path string
+	var cmd *FlagSet
+	var raw, args []string
+	fs.Use(func(ctx context.Context, h Handler) {
+		path = CommandPath(ctx)
+		cmd = CommandFromContext(ctx)
+		h(ctx)
+	})
+	add.Handle(func(ctx context.Context) {
+		raw, args = RawArgs(ctx), Args(ctx)
+	})
+
+	if _, err := fs.Run(context.Background(), "remote", "add", "origin", "-v", "git@x"); err == nil {
+		t.Fatalf("unknown option -v should fail")
+	}
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "remote", "add", "origin", "git@x"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if path != "app remote add" || !sliceEqual(raw, "remote", "add", "origin", "git@x") || !sliceEqual(args, "origin", "git@x") {
+		t.Fatalf("path: %q, raw: %q, args: %q", path, raw, args)
+	}
+	if cmd != add || cmd.Name() != "add" || cmd.FullName() != path || cmd.Description() != "add a remote" {
+		t.Fatalf("command from context: %v, %q, %q", cmd.Name(), cmd.FullName(), cmd.Description())
+	}
+	if CommandPath(context.Background()) != "" || RawArgs(context.Background()) != nil || CommandFromContext(context.Background()) != nil {
+		t.Fatalf("metadata outside Run")
+	}
+}
+
+func TestWriteUsage(t *testing.T) {
+	fs := New("app", "write usage")
+	fs.Str('o', "output", "out.txt", "output file\nsecond line")
+	fs.Handle(func(context.Context) {})
+	for i := 0; i < 100; i++ {
+		fs.Cmd(fmt.Sprintf("cmd%v", i), "sub command")
+	}
+
+	b := new(strings.Builder)
+	if err := fs.WriteUsage(b); err != nil {
+		t.Fatalf("write usage: %v", err)
+	}
+	if b.String() != fs.Usage()+"\n" {
+		t.Fatalf("write usage:\n%q\nusage:\n%q", b.String(), fs.Usage())
+	}
+}
+
+func TestUsageCache(t *testing.T) {
+	fs := New("app", "usage cache")
+	fs.Str('o', "output", "out.txt", "output file")
+	build := fs.Cmd("build", "build project")
+	build.Handle(func(context.Context) {})
+	fs.Handle(func(context.Context) {})
+
+	usage := fs.Usage()
+	if fs.Usage() != usage || fs.usage != usage {
+		t.Fatalf("usage not cached")
+	}
+
+	// every change to the registration invalidates the cached usage
+	changes := []func(){
+		func() { fs.Int('n', "num", 1, "number") },
+		func() { fs.Cmd("test", "run tests") },
+		func() { fs.Flag("output").Env("APP_OUTPUT") },
+		func() { build.Deprecate("use make") },
+		func() { build.Localize("zh", "构建", "构建项目"); fs.SetLocale("zh") },
+		func() { build.Localize("zh", "编译", "编译项目") },
+	}
+	for i, change := range changes {
+		change()
+		if s := fs.Usage(); s == usage {
+			t.Fatalf("change %v: usage not updated:\n%v", i, s)
+		} else {
+			usage = s
+		}
+	}
+
+	b := new(strings.Builder)
+	if err := fs.WriteUsage(b); err != nil || b.String() != usage+"\n" {
+		t.Fatalf("write cached usage: %q, %v", b.String(), err)
+	}
+}
+
+func BenchmarkUsage(b *testing.B) {
+	fs := New("app", "")
+	for i := 0; i < 20; i++ {
+		fs.Int(0, fmt.Sprintf("opt%02d", i), i, "option")
+		fs.Cmd(fmt.Sprintf("cmd%02d", i), "sub command")
+	}
+	fs.Handle(func(context.Context) {})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fs.Usage()
+	}
+}
@@ -0,0 +1,183 @@
+// Package flagstest提供测试flags命令行程序的工具：以给定参数执行FlagSet，捕获stdout/stderr，
+// 注入环境变量及固定时间，并断言匹配到的命令及参数值，无需编译、执行二进制文件。
+package flagstest
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eachain/flags"
+)
+
+// Result：一次执行的结果
+type Result struct {
+	Command *flags.FlagSet // 匹配到的命令，出错时为出错的命令
+	Path    string         // 命令完整名称
+	Args    []string       // 位置参数
+	Stdout  string
+	Stderr  string
+	Err     error
+}
+
+type config struct {
+	ctx   context.Context
+	env   map[string]string
+	now   time.Time
+	stdin string
+}
+
+// Option：Run的选项
+type Option func(*config)
+
+// Env：以env代替进程环境变量，未包含的环境变量视为未设置。
+func Env(env map[string]string) Option {
+	return func(c *config) { c.env = env }
+}
+
+// Clock：固定当前时间，见flags.FlagSet.SetClock。
+func Clock(now time.Time) Option {
+	return func(c *config) { c.now = now }
+}
+
+// Stdin：以s作为stdin内容。
+func Stdin(s string) Option {
+	return func(c *config) { c.stdin = s }
+}
+
+// Context：执行时使用的ctx，默认为context.Background()。
+func Context(ctx context.Context) Option {
+	return func(c *config) { c.ctx = ctx }
+}
+
+// Run：重置fs后以args执行，返回匹配到的命令、stdout、stderr及错误。
+// 执行期间替换fs的标准输入输出（见flags.FlagSet.SetStdio），不修改os.Stdin、os.Stdout、os.Stderr，
+// Handler需通过fs.Stdout()等输出才能被捕获。同一fs不能在并行测试中使用，fs不能已Seal。
+// 执行完成后恢复原有的标准输入输出。
+// Env及Clock选项仅在本次执行期间生效，未给出时使用fs原有的设置（见flags.FlagSet.SetLookupEnv、SetClock），
+// 执行完成后恢复原有设置。
+func Run(t testing.TB, fs *flags.FlagSet, args []string, opts ...Option) *Result {
+	t.Helper()
+	c := &config{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	lookupEnv, clock := fs.LookupEnvFunc(), fs.ClockFunc()
+	defer func() { fs.SetLookupEnv(lookupEnv).SetClock(clock) }()
+	if c.env != nil {
+		fs.SetLookupEnv(func(key string) (string, bool) {
+			val, ok := c.env[key]
+			return val, ok
+		})
+	}
+	if !c.now.IsZero() {
+		fs.SetClock(func() time.Time { return c.now })
+	}
+
+	stdin, stdout, stderr := tempFile(t, c.stdin), tempFile(t, ""), tempFile(t, "")
+	oldStdin, oldStdout, oldStderr := fs.Stdio()
+	fs.SetStdio(stdin, stdout, stderr)
+	defer fs.SetStdio(oldStdin, oldStdout, oldStderr)
+
+	fs.Reset()
+	m, err := fs.Exec(c.ctx, args...)
+	return &Result{
+		Command: m.Command,
+		Path:    m.Path,
+		Args:    m.Args,
+		Stdout:  readFile(t, stdout),
+		Stderr:  readFile(t, stderr),
+		Err:     err,
+	}
+}
+
+func tempFile(t testing.TB, content string) *os.File {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "flagstest")
+	if err != nil {
+		t.Fatalf("flagstest: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	if content != "" {
+		if _, err = f.WriteString(content); err == nil {
+			_, err = f.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			t.Fatalf("flagstest: %v", err)
+		}
+	}
+	return f
+}
+
+func readFile(t testing.TB, f *os.File) string {
+	t.Helper()
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("flagstest: %v", err)
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("flagstest: %v", err)
+	}
+	return string(b)
+}
+
+// AssertCommand：断言匹配到的命令完整名称为path，如"app remote add"。
+func (r *Result) AssertCommand(t testing.TB, path string) {
+	t.Helper()
+	if r.Path != path {
+		t.Fatalf("flagstest: matched command %q, expected %q", r.Path, path)
+	}
+}
+
+// AssertNoError：断言执行成功。
+func (r *Result) AssertNoError(t testing.TB) {
+	t.Helper()
+	if r.Err != nil {
+		t.Fatalf("flagstest: unexpected error: %v", r.Err)
+	}
+}
+
+// AssertSet：断言参数未取默认值，即由命令行、环境变量、配置文件或--last设置（Source不为SourceDefault）。
+func (r *Result) AssertSet(t testing.TB, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if src := r.flag(t, name).Source(); src == flags.SourceDefault {
+			t.Fatalf("flagstest: option %v not set", name)
+		}
+	}
+}
+
+// AssertNotSet：断言参数未被任何来源设置，取默认值。
+func (r *Result) AssertNotSet(t testing.TB, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if src := r.flag(t, name).Source(); src != flags.SourceDefault {
+			t.Fatalf("flagstest: option %v set by %v", name, src)
+		}
+	}
+}
+
+// AssertValue：断言参数当前值为want。
+func (r *Result) AssertValue(t testing.TB, name string, want any) {
+	t.Helper()
+	if got := r.flag(t, name).Value(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("flagstest: option %v = %#v, expected %#v", name, got, want)
+	}
+}
+
+func (r *Result) flag(t testing.TB, name string) (f *flags.Flag) {
+	t.Helper()
+	if r.Command == nil {
+		t.Fatalf("flagstest: no matched command")
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			t.Fatalf("flagstest: %v", err)
+		}
+	}()
+	return r.Command.Flag(name)
+}
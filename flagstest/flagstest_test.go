@@ -0,0 +1,83 @@
+package flagstest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eachain/flags"
+)
+
+func newApp() *flags.FlagSet {
+	fs := flags.New("app", "flagstest")
+	output := fs.Str('o', "output", "out.txt", "")
+	fs.Flag("output").Env("APP_OUTPUT")
+	verbose := fs.Bool('v', "verbose", false, "")
+	var since time.Time
+	fs.AnyVar(&since, flags.NoShort, "since", func() time.Time { return fs.Now().Add(-time.Hour) }, "")
+
+	greet := fs.Cmd("greet", "")
+	token := greet.Str(flags.NoShort, "token", "", "")
+	greet.Flag("token").FromStdin()
+	greet.Handle(func(ctx context.Context) {
+		fmt.Fprintf(fs.Stdout(), "hello %v %v\n", *output, *token)
+		if *verbose {
+			fmt.Fprintln(fs.Stderr(), since.Format(time.RFC3339))
+		}
+	})
+	return fs
+}
+
+func TestRun(t *testing.T) {
+	fs := newApp()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := Run(t, fs, []string{"greet", "-v", "--token", "-"},
+		Env(map[string]string{"APP_OUTPUT": "env.txt"}), Clock(now), Stdin("s3cr3t\n"))
+
+	r.AssertNoError(t)
+	r.AssertCommand(t, "app greet")
+	r.AssertSet(t, "verbose", "output", "token")
+	r.AssertNotSet(t, "since")
+	r.AssertValue(t, "output", "env.txt")
+	r.AssertValue(t, "since", now.Add(-time.Hour))
+	if r.Stdout != "hello env.txt s3cr3t\n" || r.Stderr != "2024-01-02T02:04:05Z\n" {
+		t.Fatalf("stdout: %q, stderr: %q", r.Stdout, r.Stderr)
+	}
+
+	r = Run(t, fs, []string{"greet"})
+	r.AssertNoError(t)
+	r.AssertValue(t, "output", "out.txt")
+	r.AssertNotSet(t, "output", "verbose")
+
+	r = Run(t, fs, []string{"greet", "--unknown"})
+	if r.Err == nil || !strings.Contains(r.Err.Error(), "unknown option") || r.Path != "app greet" {
+		t.Fatalf("unknown option: %v, %v", r.Path, r.Err)
+	}
+}
+
+func TestRunRestoresHooks(t *testing.T) {
+	fs := newApp()
+	lookupEnv := func(key string) (string, bool) { return "custom.txt", key == "APP_OUTPUT" }
+	fixed := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var stdout bytes.Buffer
+	fs.SetLookupEnv(lookupEnv).SetClock(func() time.Time { return fixed }).SetStdio(nil, &stdout, nil)
+
+	// options apply to this run only
+	r := Run(t, fs, []string{"greet"}, Env(map[string]string{"APP_OUTPUT": "env.txt"}), Clock(fixed.Add(time.Hour)))
+	r.AssertValue(t, "output", "env.txt")
+	r.AssertValue(t, "since", fixed)
+	if v, _ := fs.LookupEnvFunc()("APP_OUTPUT"); v != "custom.txt" || !fs.Now().Equal(fixed) {
+		t.Fatalf("hooks not restored: %v, %v", v, fs.Now())
+	}
+	if _, w, _ := fs.Stdio(); w != &stdout || stdout.Len() != 0 {
+		t.Fatalf("stdio not restored: %v, %q", w, stdout.String())
+	}
+
+	// without options the hooks of fs are used
+	r = Run(t, fs, []string{"greet"})
+	r.AssertValue(t, "output", "custom.txt")
+	r.AssertValue(t, "since", fixed.Add(-time.Hour))
+}
@@ -0,0 +1,59 @@
+// Package goplugin从Go plugin（.so）文件加载子命令，便于第三方单独发布子命令。
+// 单独成包是因为引入标准库plugin会使程序动态链接，不应强加给所有使用flags的程序。
+package goplugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+
+	"github.com/eachain/flags"
+)
+
+// Symbol：plugin中用于注册子命令的导出函数名称，其类型须为func(parent *flags.FlagSet)。
+const Symbol = "Register"
+
+// Load：加载Go plugin（.so）文件，调用其导出的Register函数在parent下注册子命令：
+//
+//	// go build -buildmode=plugin -o hello.so
+//	func Register(parent *flags.FlagSet) {
+//		parent.Cmd("hello", "say hello").Handle(func(context.Context) { fmt.Println("hello") })
+//	}
+//
+// plugin须与主程序使用相同版本的Go及flags编译，且仅部分平台支持，见标准库plugin。
+func Load(parent *flags.FlagSet, path string) (err error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("goplugin: load %v: %w", path, err)
+	}
+	sym, err := p.Lookup(Symbol)
+	if err != nil {
+		return fmt.Errorf("goplugin: load %v: %w", path, err)
+	}
+	register, ok := sym.(func(*flags.FlagSet))
+	if !ok {
+		return fmt.Errorf("goplugin: load %v: symbol %v type %T, want func(*flags.FlagSet)", path, Symbol, sym)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("goplugin: load %v: %v", path, r)
+		}
+	}()
+	register(parent)
+	return nil
+}
+
+// LoadDir：按文件名顺序加载dir目录下所有.so文件，见Load，遇到错误即停止。
+func LoadDir(parent *flags.FlagSet, dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return fmt.Errorf("goplugin: load %v: %w", dir, err)
+	}
+	for _, path := range paths {
+		if err = Load(parent, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
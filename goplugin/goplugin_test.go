@@ -0,0 +1,30 @@
+package goplugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eachain/flags"
+)
+
+func TestLoad(t *testing.T) {
+	fs := flags.New("app", "go plugin")
+	dir := t.TempDir()
+
+	if err := LoadDir(fs, dir); err != nil {
+		t.Fatalf("load empty dir: %v", err)
+	}
+	if err := Load(fs, filepath.Join(dir, "missing.so")); err == nil {
+		t.Fatalf("load missing plugin: no err")
+	}
+
+	os.WriteFile(filepath.Join(dir, "bad.so"), []byte("not a plugin"), 0644)
+	if err := LoadDir(fs, dir); err == nil || !strings.Contains(err.Error(), "bad.so") {
+		t.Fatalf("load bad plugin: %v", err)
+	}
+	if usage := fs.Usage(); strings.Contains(usage, "Commands:") {
+		t.Fatalf("bad plugin registered commands: %v", usage)
+	}
+}
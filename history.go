@@ -0,0 +1,132 @@
+package flags
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// RememberLast：记录每个命令最近一次成功执行时命令行中给出的参数值，并开启内置参数--last。
+// Run时如出现--last，命令行中未给出的参数使用该命令上次记录的值，优先级高于环境变量、配置文件及默认值，
+// 来源为SourceLast，适用于参数较多、需要反复调整执行的场景，如"app train --last --lr 0.01"。
+// 记录保存在JSON格式的状态文件path中，为空时为$XDG_STATE_HOME/<app>/last.json（未设置时为~/.local/state/<app>/last.json）。
+// 通过Flag.Secret标记的参数不会被记录；保存失败时产生警告（见OnWarning），不影响执行结果。开启后帮助信息中列出--last。仅根命令有效。
+func (fs *FlagSet) RememberLast(path string) *FlagSet {
+	fs.touch()
+	root := fs.root()
+	root.rememberLast = true
+	root.lastPath = path
+	return fs
+}
+
+// lastFile：状态文件路径。
+func (fs *FlagSet) lastFile() (string, error) {
+	root := fs.root()
+	if root.lastPath != "" {
+		return root.lastPath, nil
+	}
+	dir, ok := root.getenv("XDG_STATE_HOME")
+	if !ok || !filepath.IsAbs(dir) {
+		home, ok := root.getenv("HOME")
+		if !ok || home == "" {
+			var err error
+			if home, err = os.UserHomeDir(); err != nil {
+				return "", err
+			}
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, root.name, "last.json"), nil
+}
+
+// readLast：读取状态文件，key为命令完整名称，value为各参数的值，参数名称同配置文件。文件不存在时返回空。
+func (fs *FlagSet) readLast() (map[string]map[string]any, error) {
+	path, err := fs.lastFile()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var last map[string]map[string]any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err = dec.Decode(&last); err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	if last == nil {
+		last = map[string]map[string]any{}
+	}
+	return last, nil
+}
+
+// lastValues：该命令上次记录的参数值。
+func (fs *FlagSet) lastValues() (map[string]any, error) {
+	all, err := fs.readLast()
+	if err != nil {
+		return nil, fmt.Errorf("%v: read last flags: %w", fs.fullName(), err)
+	}
+	return all[fs.fullName()], nil
+}
+
+// saveLast：记录各命令命令行中给出的参数值，包括通过--last沿用的值。
+func (fs *FlagSet) saveLast(steps []step) {
+	all, err := fs.readLast()
+	if err == nil {
+		for _, s := range steps {
+			if s.cmd.plugin != "" {
+				continue
+			}
+			vals := make(map[string]any)
+			s.cmd.eachParam(func(p *param) bool {
+				if (p.source == SourceFlag || p.source == SourceLast) && !p.secret {
+					vals[p.configKey()] = configValue(p, reflect.ValueOf(p.ptr).Elem())
+				}
+				return true
+			})
+			all[s.cmd.fullName()] = vals
+		}
+		err = fs.writeLast(all)
+	}
+	if err != nil {
+		fs.notice("", "save last flags: %v", err)
+	}
+}
+
+// writeLast：先写入临时文件再重命名，避免并发执行或中断时留下不完整的状态文件。
+func (fs *FlagSet) writeLast(all map[string]map[string]any) error {
+	path, err := fs.lastFile()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".last-*.json")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
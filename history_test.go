@@ -0,0 +1,78 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRememberLast(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "last.json")
+	fs := New("app", "").RememberLast(path)
+	verbose := fs.Bool('v', "verbose", false, "")
+	train := fs.Cmd("train", "")
+	lr := train.Float64(0, "lr", 0.1, "")
+	epochs := train.Int('e', "epochs", 10, "")
+	layers := Slice[int](train, 'l', "layers", nil, "")
+	token := train.Str(0, "token", "", "")
+	train.Flag("token").Secret()
+	logDir := train.Str(0, "log-dir", "", "")
+	train.Flag("log-dir").DefaultFrom(func() string { return strings.Repeat("e", *epochs) }, "epochs")
+	fail := false
+	train.HandleE(func(context.Context) error {
+		if fail {
+			return os.ErrInvalid
+		}
+		return nil
+	})
+
+	run := func(args ...string) {
+		t.Helper()
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), args...); err != nil && !fail {
+			t.Fatalf("run %q: %v", args, err)
+		}
+	}
+
+	run("-v", "train", "--lr", "0.5", "-e", "3", "-l", "64", "-l", "32", "--token", "secret")
+	run("train", "--last", "-e", "5")
+	if !*verbose || *lr != 0.5 || *epochs != 5 || !sliceEqual(*layers, 64, 32) || *token != "" || *logDir != "eeeee" {
+		t.Fatalf("last: verbose: %v, lr: %v, epochs: %v, layers: %v, token: %q, log-dir: %q",
+			*verbose, *lr, *epochs, *layers, *token, *logDir)
+	}
+	if src := train.Flag("lr").Source(); src != SourceLast {
+		t.Fatalf("lr source: %v", src)
+	}
+
+	// values reused via --last are recorded again, failed runs are not recorded
+	fail = true
+	run("train", "-e", "7")
+	fail = false
+	run("train", "--last")
+	if *lr != 0.5 || *epochs != 5 || *logDir != "eeeee" {
+		t.Fatalf("last after failure: lr: %v, epochs: %v, log-dir: %q", *lr, *epochs, *logDir)
+	}
+
+	// --last before the subcommand, last values take precedence over env
+	train.Flag("lr").Env("APP_LR")
+	fs.SetLookupEnv(func(key string) (string, bool) { return "0.9", key == "APP_LR" })
+	run("--last", "train")
+	if *lr != 0.5 || *epochs != 5 || !*verbose {
+		t.Fatalf("last before subcommand: lr: %v, epochs: %v, verbose: %v", *lr, *epochs, *verbose)
+	}
+	fs.SetLookupEnv(nil)
+	if usage := train.Usage(); !strings.Contains(usage, "  --last\n") {
+		t.Fatalf("usage: %v", usage)
+	}
+
+	// without --last nothing is reused
+	run("train")
+	if *lr != 0.1 || *verbose {
+		t.Fatalf("without last: lr: %v, verbose: %v", *lr, *verbose)
+	}
+	if b, err := os.ReadFile(path); err != nil || strings.Contains(string(b), "secret") {
+		t.Fatalf("state file: %s, %v", b, err)
+	}
+}
@@ -0,0 +1,72 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var httpKey = new(int)
+
+// HTTPResponseWriter：在通过HTTPHandler执行的Handler中获取http.ResponseWriter，非HTTP请求返回nil。
+func HTTPResponseWriter(ctx context.Context) http.ResponseWriter {
+	w, _ := ctx.Value(httpKey).(http.ResponseWriter)
+	return w
+}
+
+type httpHandler struct {
+	mu sync.Mutex
+	fs *FlagSet
+}
+
+// HTTPHandler：将命令树挂载为http.Handler，便于运维工具远程执行本地命令。
+// URL路径映射为子命令，如"/db/migrate"对应"db migrate"；query及form参数按长参数名映射为"--name=value"。
+// Handler使用请求的context执行，可通过HTTPResponseWriter获取http.ResponseWriter输出结果。
+// 由于参数解析结果写入共享变量，请求之间串行执行，每次执行前调用Reset重置参数。
+func HTTPHandler(fs *FlagSet) http.Handler {
+	return &httpHandler{fs: fs}
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var args []string
+	for _, name := range strings.Split(r.URL.Path, "/") {
+		if name != "" {
+			args = append(args, name)
+		}
+	}
+	keys := make([]string, 0, len(r.Form))
+	for key := range r.Form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, val := range r.Form[key] {
+			args = append(args, fmt.Sprintf("--%v=%v", key, val))
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.fs.Reset()
+	ctx := context.WithValue(r.Context(), httpKey, w)
+	usage, err := h.fs.Run(ctx, args...)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrHelp):
+		fmt.Fprintln(w, usage)
+	case errors.Is(err, ErrNoExecFunc):
+		http.Error(w, usage, http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
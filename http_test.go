@@ -0,0 +1,46 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	fs := New("http", "")
+	greet := fs.Cmd("greet", "")
+	name := greet.Str('n', "name", "world", "name to greet")
+	greet.Handle(func(ctx context.Context) {
+		fmt.Fprintf(HTTPResponseWriter(ctx), "hello %v", *name)
+	})
+
+	srv := httptest.NewServer(HTTPHandler(fs))
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("http get %v: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if code, body := get("/greet?name=flags"); code != http.StatusOK || body != "hello flags" {
+		t.Fatalf("http greet: %v %q", code, body)
+	}
+	if code, body := get("/greet"); code != http.StatusOK || body != "hello world" {
+		t.Fatalf("http greet default: %v %q", code, body)
+	}
+	if code, _ := get("/greet?unknown=1"); code != http.StatusBadRequest {
+		t.Fatalf("http unknown option: %v", code)
+	}
+	if code, body := get("/"); code != http.StatusNotFound || !strings.Contains(body, "greet") {
+		t.Fatalf("http root: %v %q", code, body)
+	}
+}
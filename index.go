@@ -0,0 +1,31 @@
+package flags
+
+// paramIndex：当前命令可见参数按短参数、长参数名称建立的索引，用于解析时O(1)查找参数。
+type paramIndex struct {
+	short map[string]*param
+	long  map[string]*param
+}
+
+// index：参数索引，首次解析时建立，当前命令注册参数或参数名称规范化后失效。
+// 子命令继承的参数不会因父命令之后注册的参数而改变，因此父命令注册参数不影响子命令的索引。
+func (fs *FlagSet) index() *paramIndex {
+	if fs.idx != nil {
+		return fs.idx
+	}
+	n := fs.numParams()
+	idx := &paramIndex{
+		short: make(map[string]*param, n),
+		long:  make(map[string]*param, n),
+	}
+	fs.eachParam(func(p *param) bool {
+		if _, ok := idx.short[p.short]; !ok && p.short != "" {
+			idx.short[p.short] = p
+		}
+		if _, ok := idx.long[p.long]; !ok && p.long != "" {
+			idx.long[p.long] = p
+		}
+		return true
+	})
+	fs.idx = idx
+	return idx
+}
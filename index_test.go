@@ -0,0 +1,46 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestParamIndex(t *testing.T) {
+	fs := New("app", "")
+	a := fs.Str('a', "alpha", "", "")
+	fs.Handle(func(context.Context) {})
+	if _, err := fs.Run(context.Background(), "-a", "1"); err != nil || *a != "1" {
+		t.Fatalf("run: %v, alpha: %q", err, *a)
+	}
+
+	// registering after a parse invalidates the index
+	b := fs.Str('b', "beta", "", "")
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "--beta=2", "--alpha", "3"); err != nil || *b != "2" || *a != "3" {
+		t.Fatalf("run: %v, alpha: %q, beta: %q", err, *a, *b)
+	}
+
+	// renormalizing long names invalidates the index
+	fs.AutoAlias()
+	fs.Str(0, "gamma_delta", "", "")
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "--gamma_delta=4"); err != nil || fs.Flag("gamma-delta").Value() != "4" {
+		t.Fatalf("run normalized: %v", err)
+	}
+}
+
+func BenchmarkLongLookup(b *testing.B) {
+	fs := New("app", "")
+	for i := 0; i < 200; i++ {
+		fs.Bool(0, fmt.Sprintf("flag-%03d", i), false, "")
+	}
+	fs.Handle(func(context.Context) {})
+	args := []string{"--flag-199", "--flag-100=false"}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fs.Run(ctx, args...)
+	}
+}
@@ -0,0 +1,56 @@
+package flags
+
+import "reflect"
+
+// FlagInfo：参数的只读描述，见FlagSet.Flags，可用于生成文档、补全脚本或调试输出。
+type FlagInfo struct {
+	Short       string   // 短参数名称，不带"-"，没有时为空
+	Long        string   // 长参数名称，不带"--"，没有时为空
+	Type        string   // 参数值类型，同Usage中显示的类型
+	Default     string   // 默认值的命令行形式，没有默认值时为空
+	Description string   // 参数描述
+	Separators  []string // slice元素、map键值、嵌套map内外层key的分隔符，依次最多3个，标量参数为空
+	Env         string   // 绑定的环境变量，见Flag.Env
+	Set         bool     // 是否未取默认值，即通过命令行参数、环境变量、配置文件或--last赋值，同Source != SourceDefault
+	Source      Source   // 参数值来源
+	Value       string   // 当前值的命令行形式，Flag.Secret标记的参数显示为"******"
+}
+
+// Flags：当前命令可见的参数，包括继承自父命令的参数，按注册顺序排列。
+// 返回的是参数的副本，修改不影响FlagSet；Set、Source、Value需在解析完成后调用才有意义。
+func (fs *FlagSet) Flags() []FlagInfo {
+	list := make([]FlagInfo, 0, fs.numParams())
+	fs.eachParam(func(p *param) bool {
+		list = append(list, p.info())
+		return true
+	})
+	return list
+}
+
+func (p *param) info() FlagInfo {
+	info := FlagInfo{
+		Short:       p.short,
+		Long:        p.long,
+		Type:        p.typ,
+		Description: p.desc,
+		Env:         p.env,
+		Set:         p.source != SourceDefault,
+		Source:      p.source,
+		Value:       formatValue(p, reflect.ValueOf(p.ptr).Elem()),
+	}
+	if p.dft != nil {
+		info.Default = formatValue(p, reflect.ValueOf(p.dft))
+	}
+	switch {
+	case p.isSlice():
+		info.Separators = []string{p.sep1}
+	case p.kind == reflect.Map && p.elem.kind == reflect.Map:
+		info.Separators = []string{p.sep1, p.sep2, p.sep3}
+	case p.kind == reflect.Map:
+		info.Separators = []string{p.sep1, p.sep2}
+	}
+	if p.secret {
+		info.Value = "******"
+	}
+	return info
+}
@@ -0,0 +1,36 @@
+package flags
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFlags(t *testing.T) {
+	fs := New("app", "")
+	fs.Str('o', "output", "out.txt", "output file")
+	fs.Str(0, "token", "", "api token")
+	fs.Flag("token").Secret().Env("APP_TOKEN")
+	build := fs.Cmd("build", "")
+	Slice[string](build, 't', "tags", []string{"a,b", "c"}, "tags")
+	Map[string, time.Duration](build, 0, "timeouts", nil, "", ";", "=")
+	NestedMap[string, int](build, 0, "limits", nil, "")
+	build.Handle(func(context.Context) {})
+	fs.SetLookupEnv(func(string) (string, bool) { return "secret", true })
+
+	if _, err := fs.Run(context.Background(), "-o", "x.txt", "build", "--timeouts", "b=2s;a=1s", "--limits", "x.y:1,x.z:2"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	want := []FlagInfo{
+		{Short: "o", Long: "output", Type: "string", Default: "out.txt", Description: "output file", Set: true, Source: SourceFlag, Value: "x.txt"},
+		{Long: "token", Type: "string", Env: "APP_TOKEN", Description: "api token", Set: true, Source: SourceEnv, Value: "******"},
+		{Short: "t", Long: "tags", Type: "[]string", Default: `a\,b,c`, Description: "tags", Separators: []string{","}, Value: `a\,b,c`},
+		{Long: "timeouts", Type: "map[string]time.Duration", Separators: []string{";", "="}, Set: true, Source: SourceFlag, Value: "a=1s;b=2s"},
+		{Long: "limits", Type: "map[string]map[string]int", Separators: []string{",", ":", "."}, Set: true, Source: SourceFlag, Value: "x.y:1,x.z:2"},
+	}
+	if got := build.Flags(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("flags:\n%+v\nwant:\n%+v", got, want)
+	}
+}
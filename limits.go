@@ -0,0 +1,83 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrLimit：参数超出Limits设置的限制。
+var ErrLimit = errors.New("limit exceeded")
+
+// Limits：解析限制，用于解析不可信的参数（如服务端接收的命令行），防止超大或构造的输入耗尽资源。
+// 各项为0时不限制。
+type Limits struct {
+	MaxArgs    int // 参数个数上限
+	MaxArgLen  int // 单个参数的字节数上限
+	MaxElems   int // 单个slice、map参数的元素个数上限
+	MaxRepeats int // 同一参数在命令行中出现次数的上限
+}
+
+// UntrustedLimits：适用于解析不可信输入的推荐限制。
+var UntrustedLimits = Limits{
+	MaxArgs:    1024,
+	MaxArgLen:  64 << 10,
+	MaxElems:   1024,
+	MaxRepeats: 64,
+}
+
+// SetLimits：设置解析限制，超出限制时解析报错，错误可用errors.Is(err, ErrLimit)判断。仅根命令有效。
+func (fs *FlagSet) SetLimits(limits Limits) *FlagSet {
+	fs.unsealed()
+	fs.root().limits = limits
+	return fs
+}
+
+// checkArgsLimit：检查参数个数及长度。
+func (fs *FlagSet) checkArgsLimit(args []string) error {
+	limits := fs.root().limits
+	if limits.MaxArgs > 0 && len(args) > limits.MaxArgs {
+		return fmt.Errorf("%v: %w: %v args, max %v", fs.name, ErrLimit, len(args), limits.MaxArgs)
+	}
+	if limits.MaxArgLen > 0 {
+		for i, arg := range args {
+			if len(arg) > limits.MaxArgLen {
+				return fmt.Errorf("%v: %w: arg %v has %v bytes, max %v", fs.name, ErrLimit, i, len(arg), limits.MaxArgLen)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRepeats：记录参数出现次数，并检查是否超出上限。
+func (fs *FlagSet) checkRepeats(args *arguments, arg string, p *param) error {
+	max := fs.root().limits.MaxRepeats
+	if max <= 0 {
+		return nil
+	}
+	if args.repeats == nil {
+		args.repeats = make(map[*param]int)
+	}
+	args.repeats[p]++
+	if n := args.repeats[p]; n > max {
+		return fmt.Errorf("%v: %w: option %v repeated %v times, max %v", fs.name, ErrLimit, arg, n, max)
+	}
+	return nil
+}
+
+// checkElems：检查slice、map参数在已有元素基础上再增加n个元素后是否超出上限。
+// s不为空时，n为s按sep分割后的个数。
+func (fs *FlagSet) checkElems(arg string, p *param, n int, s, sep string) error {
+	max := fs.root().limits.MaxElems
+	if max <= 0 {
+		return nil
+	}
+	if s != "" && sep != "" {
+		n = strings.Count(s, sep) + 1
+	}
+	if n += reflect.ValueOf(p.ptr).Elem().Len(); n > max {
+		return fs._parseParamErr(arg, fmt.Errorf("%w: %v elements, max %v", ErrLimit, n, max))
+	}
+	return nil
+}
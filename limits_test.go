@@ -0,0 +1,91 @@
+package flags
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	fs := New("app", "").SetLimits(Limits{MaxArgs: 8, MaxArgLen: 16, MaxElems: 3, MaxRepeats: 2})
+	fs.Str('o', "output", "", "")
+	Slice[string](fs, 't', "tags", nil, "")
+	fs.Flag("tags").Env("APP_TAGS")
+	Map[string, int](fs, 'm', "map", nil, "")
+	fs.SetLookupEnv(func(string) (string, bool) { return "a,b,c,d", true })
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{strings.Split("a b c d e f g h i", " "), "app: limit exceeded: 9 args, max 8"},
+		{[]string{"-o", strings.Repeat("x", 17)}, "app: limit exceeded: arg 1 has 17 bytes, max 16"},
+		{[]string{"-o", "a", "-o", "b", "--output", "c"}, "app: limit exceeded: option --output repeated 3 times, max 2"},
+		{[]string{"-m", "a:1,b:2,c:3,d:4"}, "app: parse option -m: limit exceeded: 4 elements, max 3"},
+		{[]string{"-t", "a", "-t", "b"}, ""},
+		{nil, "app: parse option $APP_TAGS: limit exceeded: 4 elements, max 3"},
+	}
+	for i, tt := range tests {
+		fs.Reset()
+		_, err := fs.Parse(tt.args...)
+		if tt.want == "" {
+			if err != nil {
+				t.Fatalf("case %v: %v", i, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrLimit) || err.Error() != tt.want {
+			t.Fatalf("case %v: %v", i, err)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("-v --output a.txt build --tags x,y -- rest")
+	f.Add("--map 'a:1,b:2' -n -1 build 'quoted arg' --level=3")
+	f.Add("--map a:1,a:2,\\:b:3 --nested x.y:1,x.z:2 build --tags=")
+	f.Add("build --no-color -vvv -- -- --")
+
+	f.Fuzz(func(t *testing.T, line string) {
+		args, err := SplitLine(line)
+		if err != nil {
+			return
+		}
+
+		fs := New("app", "").SetLimits(UntrustedLimits).OnWarning(func(Warning) {})
+		fs.Bool('v', "verbose", false, "")
+		fs.Str('o', "output", "", "")
+		fs.Int('n', "num", 0, "")
+		Map[string, int](fs, 'm', "map", nil, "")
+		NestedMap[string, int](fs, 0, "nested", nil, "")
+		build := fs.Cmd("build", "")
+		Slice[string](build, 't', "tags", nil, "")
+		build.Bool(0, "color", true, "")
+		build.Flag("color").Negatable()
+		build.Uint8(0, "level", 0, "")
+		Rest[string](build, "files", 0, "")
+
+		// must not panic, and must not change bound variables
+		m, values, err := fs.ParseValues(args...)
+		if err == nil && (m == nil || values == nil) {
+			t.Fatalf("parse %q: nil result", args)
+		}
+		_ = fs.Usage()
+	})
+}
+
+func FuzzSplitLine(f *testing.F) {
+	f.Add(`a "b c" 'd e' f\ g`)
+	f.Add("a \\\nb \"c\\\"d\"")
+	f.Fuzz(func(t *testing.T, line string) {
+		args, err := SplitLine(line)
+		if err != nil {
+			return
+		}
+		for _, arg := range args {
+			if len(arg) > len(line) {
+				t.Fatalf("split %q: arg %q longer than line", line, arg)
+			}
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package flags
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SplitLine：按shell规则将一行命令拆分为参数列表。
+// 支持空白分隔、单引号（内容原样保留）、双引号（支持\"、\\、\$、\`转义）以及引号外的反斜杠转义。
+func SplitLine(s string) ([]string, error) {
+	var (
+		args  []string
+		arg   strings.Builder
+		inArg bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+
+		case c == '\\':
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("flags: split line: trailing backslash")
+			}
+			i++
+			if s[i] != '\n' { // 反斜杠换行为续行，整体忽略
+				arg.WriteByte(s[i])
+				inArg = true
+			}
+
+		case c == '\'':
+			j := strings.IndexByte(s[i+1:], '\'')
+			if j < 0 {
+				return nil, fmt.Errorf("flags: split line: unterminated single quote")
+			}
+			arg.WriteString(s[i+1 : i+1+j])
+			i += j + 1
+			inArg = true
+
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`\n", s[i+1]) >= 0 {
+					i++
+					if s[i] == '\n' {
+						continue
+					}
+				}
+				arg.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("flags: split line: unterminated double quote")
+			}
+			inArg = true
+
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// RunLine：将一行命令按SplitLine拆分后调用Run，适用于内嵌shell、聊天机器人、测试代码等场景。
+func (fs *FlagSet) RunLine(ctx context.Context, line string) (string, error) {
+	args, err := SplitLine(line)
+	if err != nil {
+		return fs.Usage(), err
+	}
+	return fs.Run(ctx, args...)
+}
+
+// RunScript：从r逐行读取命令并依次执行，适用于迁移脚本、测试数据等场景。
+// 空行及以"#"开头的注释行会被忽略，行尾的反斜杠表示下一行为续行。每行执行前会调用Reset重置参数。
+// 某行出错时继续执行后续各行，返回所有出错行的错误（带行号），全部成功时返回nil。
+func (fs *FlagSet) RunScript(ctx context.Context, r io.Reader) error {
+	var (
+		errs  []error
+		line  strings.Builder
+		start int // 当前命令的起始行号
+	)
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		text := sc.Text()
+		if line.Len() == 0 {
+			text = strings.TrimSpace(text)
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			start = n
+		}
+		if continued(text) {
+			line.WriteString(text)
+			line.WriteByte('\n')
+			continue
+		}
+		line.WriteString(text)
+
+		fs.Reset()
+		if _, err := fs.RunLine(ctx, line.String()); err != nil {
+			errs = append(errs, fmt.Errorf("line %v: %w", start, err))
+		}
+		line.Reset()
+	}
+	if err := sc.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	if line.Len() > 0 {
+		errs = append(errs, fmt.Errorf("line %v: unterminated line continuation", start))
+	}
+	return errors.Join(errs...)
+}
+
+// continued：行尾是否为未转义的反斜杠。
+func continued(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
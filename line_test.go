@@ -0,0 +1,88 @@
+package flags
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSplitLine(t *testing.T) {
+	cases := []struct {
+		line string
+		args []string
+	}{
+		{"", nil},
+		{"  a  b\tc ", []string{"a", "b", "c"}},
+		{`a 'b c' "d e"`, []string{"a", "b c", "d e"}},
+		{`a\ b 'c\d' "e\"f\g"`, []string{"a b", `c\d`, `e"f\g`}},
+		{`--name='' x"y"'z'`, []string{"--name=", "xyz"}},
+		// backslash-newline is a line continuation and does not start an argument
+		{"a \\\n  b", []string{"a", "b"}},
+		{"a\\\nb \\\n", []string{"ab"}},
+	}
+	for _, c := range cases {
+		args, err := SplitLine(c.line)
+		if err != nil {
+			t.Fatalf("split line %q: %v", c.line, err)
+		}
+		if !sliceEqual(args, c.args...) {
+			t.Fatalf("split line %q: %q", c.line, args)
+		}
+	}
+
+	for _, line := range []string{`a 'b`, `a "b`, `a\`} {
+		if _, err := SplitLine(line); err == nil {
+			t.Fatalf("split line %q: no err", line)
+		}
+	}
+}
+
+func TestRunLine(t *testing.T) {
+	fs := New("line", "")
+	var s string
+	sub := fs.Cmd("sub", "")
+	sub.StrVar(&s, 's', "str", "", "a string value")
+	sub.Handle(func(context.Context) {})
+
+	_, err := fs.RunLine(context.Background(), `sub -s "hello world"`)
+	if err != nil {
+		t.Fatalf("run line: %v", err)
+	}
+	if s != "hello world" {
+		t.Fatalf("run line result: %q", s)
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	fs := New("app", "script")
+	var got []string
+	add := fs.Cmd("add", "")
+	name := add.Str('n', "name", "", "")
+	tags := Slice[string](add, 't', "tag", nil, "")
+	add.Handle(func(context.Context) {
+		got = append(got, *name+":"+strings.Join(*tags, "/"))
+	})
+
+	script := `# migration
+add -n a
+
+  # indented comment
+add -n b \
+    -t x \
+    -t y
+add --unknown
+add -n 'c d'
+`
+	err := fs.RunScript(context.Background(), strings.NewReader(script))
+	if err == nil || !strings.Contains(err.Error(), "line 8: add: unknown option: --unknown") {
+		t.Fatalf("run script: %v", err)
+	}
+	if !sliceEqual(got, "a:", "b:x/y", "c d:") {
+		t.Fatalf("run script result: %q %v", got, err)
+	}
+
+	err = fs.RunScript(context.Background(), strings.NewReader("add -n x \\"))
+	if err == nil || !strings.Contains(err.Error(), "line 1: unterminated line continuation") {
+		t.Fatalf("unterminated continuation: %v", err)
+	}
+}
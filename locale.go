@@ -0,0 +1,123 @@
+package flags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// localized：命令在某一语言下的名称、别名及描述。
+type localized struct {
+	name    string
+	aliases []string
+	desc    string
+}
+
+// Localize：设置命令在locale语言下的名称、描述及别名，如cmd.Localize("zh", "构建", "编译项目")，
+// name、desc为空时沿用原名称、描述。通过SetLocale选择语言后，Usage中展示对应的名称及描述，
+// 解析时可使用对应的名称或别名匹配子命令，原名称始终有效。
+func (fs *FlagSet) Localize(locale, name, desc string, aliases ...string) *FlagSet {
+	fs.unsealed()
+	if locale == "" {
+		panic(fmt.Errorf("flags: localize command %v: empty locale", fs.name))
+	}
+	if fs.locales == nil {
+		fs.locales = make(map[string]localized)
+	}
+	fs.locales[locale] = localized{name: name, aliases: aliases, desc: desc}
+	if h := fs.holder(); h != nil {
+		h.localIdx = nil
+	}
+	fs.touch()
+	return fs
+}
+
+// holder：保存当前命令的父命令，即cmds中包含当前命令的FlagSet。
+func (fs *FlagSet) holder() *FlagSet {
+	if fs.parent != nil && fs.parent.stmt != nil {
+		return fs.parent.stmt
+	}
+	return fs.parent
+}
+
+// SetLocale：设置使用的语言，如"zh"、"zh_CN.UTF-8"，未找到完全匹配的翻译时使用语言部分（如"zh"）匹配。
+// 为空时不使用翻译，默认为空。仅根命令有效。
+func (fs *FlagSet) SetLocale(locale string) *FlagSet {
+	fs.unsealed()
+	fs.root().locale = locale
+	fs.touch()
+	return fs
+}
+
+// localized：当前语言下的翻译。
+func (fs *FlagSet) localized() (localized, bool) {
+	locale := fs.root().locale
+	if locale == "" || len(fs.locales) == 0 {
+		return localized{}, false
+	}
+	if l, ok := fs.locales[locale]; ok {
+		return l, true
+	}
+	if i := strings.IndexAny(locale, "-_."); i > 0 {
+		l, ok := fs.locales[locale[:i]]
+		return l, ok
+	}
+	return localized{}, false
+}
+
+// localName：当前语言下的命令名称。
+func (fs *FlagSet) localName() string {
+	if l, ok := fs.localized(); ok && l.name != "" {
+		return l.name
+	}
+	return fs.name
+}
+
+// localDesc：当前语言下的命令描述。
+func (fs *FlagSet) localDesc() string {
+	if l, ok := fs.localized(); ok && l.desc != "" {
+		return l.desc
+	}
+	return fs.desc
+}
+
+// localFullName：当前语言下的命令完整名称，用于Usage。
+func (fs *FlagSet) localFullName() string {
+	var names []string
+	for f := fs; f != nil; f = f.parent {
+		if f.name != "" {
+			names = append(names, f.localName())
+		}
+	}
+	for i := 0; i < len(names)/2; i++ {
+		j := len(names) - 1 - i
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, " ")
+}
+
+// subcmd：按名称查找子命令，包括当前语言下的名称及别名。当前语言下的索引在首次查找时建立。
+func (fs *FlagSet) subcmd(name string) *FlagSet {
+	if cmd := fs.cmdIdx[name]; cmd != nil {
+		return cmd
+	}
+	locale := fs.root().locale
+	if locale == "" || len(fs.cmds) == 0 {
+		return nil
+	}
+	if fs.localIdx == nil || fs.localFor != locale {
+		fs.localIdx = make(map[string]*FlagSet)
+		fs.localFor = locale
+		for _, cmd := range fs.cmds {
+			l, ok := cmd.localized()
+			if !ok {
+				continue
+			}
+			for _, name := range append([]string{l.name}, l.aliases...) {
+				if _, dup := fs.localIdx[name]; !dup && name != "" {
+					fs.localIdx[name] = cmd
+				}
+			}
+		}
+	}
+	return fs.localIdx[name]
+}
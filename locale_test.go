@@ -0,0 +1,45 @@
+package flags
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLocalize(t *testing.T) {
+	fs := New("app", "an app").Localize("zh", "", "一个应用")
+	var ran int
+	build := fs.Cmd("build", "build the project").Localize("zh", "构建", "编译项目", "编译")
+	build.Handle(func(ctx context.Context) { ran++ })
+
+	// no locale: original names only
+	if usage := fs.Usage(); !strings.Contains(usage, "  build\n    build the project") {
+		t.Fatalf("default usage:\n%v", usage)
+	}
+	if _, err := fs.Run(context.Background(), "构建"); err == nil {
+		t.Fatalf("localized name should not match without locale")
+	}
+
+	fs.SetLocale("zh_CN.UTF-8")
+	for _, name := range []string{"build", "构建", "编译"} {
+		if _, err := fs.Run(context.Background(), name); err != nil {
+			t.Fatalf("run %v: %v", name, err)
+		}
+	}
+	if ran != 3 {
+		t.Fatalf("ran: %v", ran)
+	}
+
+	usage := fs.Usage()
+	if !strings.HasPrefix(usage, "app - 一个应用") || !strings.Contains(usage, "  构建, 编译\n    编译项目") {
+		t.Fatalf("localized usage:\n%v", usage)
+	}
+	if usage := build.Usage(); !strings.HasPrefix(usage, "app 构建 - 编译项目") {
+		t.Fatalf("localized sub command usage:\n%v", usage)
+	}
+
+	m, err := fs.Parse("编译")
+	if err != nil || m.Path != "app build" {
+		t.Fatalf("parse localized alias: %+v, %v", m, err)
+	}
+}
@@ -0,0 +1,52 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+var loggerKey = new(int)
+
+// Logger：获取UseLogging构建的*slog.Logger，未设置时返回slog.Default()。
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// UseLogging：注册"--log-level"、"--log-format"（text/json）、"--log-file"参数，以及根据参数构建*slog.Logger的中间件，
+// Handler中通过Logger(ctx)获取。应在注册子命令及Handler之前调用，以便子命令继承参数及中间件。
+func UseLogging(fs *FlagSet) *FlagSet {
+	level := fs.LogLevel(NoShort, "log-level", slog.LevelInfo, "log level: debug, info, warn, error")
+	format := fs.Str(NoShort, "log-format", "text", "log format: text, json")
+	file := fs.Str(NoShort, "log-file", "", "log file, appended to; stderr if empty")
+
+	return fs.Use(func(ctx context.Context, next Handler) {
+		out := fs.Stderr()
+		if *file != "" {
+			f, err := os.OpenFile(*file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+			if err != nil {
+				fmt.Fprintf(fs.Stderr(), "flags: open log file: %v, use stderr\n", err)
+			} else {
+				defer f.Close()
+				out = f
+			}
+		}
+
+		opts := &slog.HandlerOptions{Level: *level}
+		var handler slog.Handler
+		switch *format {
+		case "json":
+			handler = slog.NewJSONHandler(out, opts)
+		default:
+			if *format != "text" {
+				fmt.Fprintf(fs.Stderr(), "flags: unknown log format %q, use text\n", *format)
+			}
+			handler = slog.NewTextHandler(out, opts)
+		}
+		next(context.WithValue(ctx, loggerKey, slog.New(handler)))
+	})
+}
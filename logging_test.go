@@ -0,0 +1,77 @@
+package flags
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogLevel(t *testing.T) {
+	fs := New("log_level", "")
+	level := fs.LogLevel('l', "level", slog.LevelWarn, "log level")
+	fs.Handle(func(context.Context) {})
+
+	cases := []struct {
+		args  []string
+		level slog.Level
+	}{
+		{nil, slog.LevelWarn},
+		{[]string{"-l", "debug"}, slog.LevelDebug},
+		{[]string{"--level=ERROR"}, slog.LevelError},
+		{[]string{"--level", "info+2"}, slog.LevelInfo + 2},
+		{[]string{"--level", "-4"}, slog.LevelDebug},
+	}
+	for _, c := range cases {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), c.args...)
+		if err != nil {
+			t.Fatalf("log level run %q: %v", c.args, err)
+		}
+		if *level != c.level {
+			t.Fatalf("log level run %q result: %v", c.args, *level)
+		}
+	}
+
+	if _, err := fs.Run(context.Background(), "-l", "verbose"); err == nil {
+		t.Fatalf("log level run: no err")
+	}
+}
+
+func TestUseLogging(t *testing.T) {
+	fs := UseLogging(New("logging", ""))
+	var enabled bool
+	fs.Handle(func(ctx context.Context) {
+		enabled = Logger(ctx).Enabled(ctx, slog.LevelDebug)
+	})
+
+	_, err := fs.Run(context.Background(), "--log-level", "debug", "--log-format", "json")
+	if err != nil {
+		t.Fatalf("logging run: %v", err)
+	}
+	if !enabled {
+		t.Fatalf("logging: debug level not enabled")
+	}
+}
+
+func TestLogFile(t *testing.T) {
+	fs := UseLogging(New("log_file", ""))
+	fs.Handle(func(ctx context.Context) {
+		Logger(ctx).Info("hello")
+	})
+
+	file := filepath.Join(t.TempDir(), "app.log")
+	_, err := fs.Run(context.Background(), "--log-file", file, "--log-format", "json")
+	if err != nil {
+		t.Fatalf("log file run: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"hello"`) {
+		t.Fatalf("log file content: %s", data)
+	}
+}
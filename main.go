@@ -0,0 +1,115 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// 退出码
+const (
+	ExitOK      = 0 // 执行成功
+	ExitFailure = 1 // 命令执行出错
+	ExitUsage   = 2 // 参数错误
+)
+
+// Main：以os.Args[1:]执行fs并退出进程，省去每个main函数中重复的样板代码：
+// -h/--help时打印帮助信息，退出码为0；命令没有Handler时将帮助信息打印到stderr，参数错误时将错误打印到stderr，退出码均为2；
+// 命令执行出错（见HandleE）时将错误打印到stderr，退出码为1，或ExitCode、ExitCodeAs设置的退出码。
+// 进程通过SetExiter设置的Exiter退出，默认为os.Exit。
+func Main(fs *FlagSet) {
+	fs.exit(fs.main(context.Background(), os.Args[1:], fs.Stderr()))
+}
+
+// Exiter：退出进程的方式，见SetExiter。
+type Exiter interface {
+	Exit(code int)
+}
+
+// ExiterFunc：函数形式的Exiter。
+type ExiterFunc func(code int)
+
+func (f ExiterFunc) Exit(code int) {
+	f(code)
+}
+
+// SetExiter：设置Main等需要退出进程时使用的Exiter，默认为os.Exit。
+// 嵌入其它程序或测试时可用于拦截退出，此时Exit返回后Main也随之返回。仅根命令有效。
+func (fs *FlagSet) SetExiter(e Exiter) *FlagSet {
+	fs.unsealed()
+	fs.root().exiter = e
+	return fs
+}
+
+func (fs *FlagSet) exit(code int) {
+	if e := fs.root().exiter; e != nil {
+		e.Exit(code)
+		return
+	}
+	os.Exit(code)
+}
+
+func (fs *FlagSet) main(ctx context.Context, args []string, stderr io.Writer) int {
+	usage, handled, err := fs.run(ctx, args, nil)
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrHelp):
+		fs.PrintUsage(usage)
+		return ExitOK
+	case handled:
+		fmt.Fprintln(stderr, err)
+		return fs.exitCode(err)
+	case errors.Is(err, ErrNoExecFunc):
+		fmt.Fprintln(stderr, usage)
+		return ExitUsage
+	}
+	fmt.Fprintln(stderr, err)
+	return ExitUsage
+}
+
+type exitCode struct {
+	match func(error) bool
+	code  int
+}
+
+// ExitCode：命令执行返回的错误满足errors.Is(err, target)时，Main以code退出，如：
+//
+//	fs.ExitCode(os.ErrNotExist, 4)
+//
+// 按注册顺序匹配，先注册的优先。
+func (fs *FlagSet) ExitCode(target error, code int) *FlagSet {
+	fs.unsealed()
+	root := fs.root()
+	root.exitCodes = append(root.exitCodes, exitCode{
+		match: func(err error) bool { return errors.Is(err, target) },
+		code:  code,
+	})
+	return fs
+}
+
+// ExitCodeAs：命令执行返回的错误可通过errors.As转为E类型时，Main以code退出，如：
+//
+//	flags.ExitCodeAs[*NotFoundError](fs, 4)
+func ExitCodeAs[E error](fs *FlagSet, code int) {
+	fs.unsealed()
+	root := fs.root()
+	root.exitCodes = append(root.exitCodes, exitCode{
+		match: func(err error) bool {
+			var e E
+			return errors.As(err, &e)
+		},
+		code: code,
+	})
+}
+
+func (fs *FlagSet) exitCode(err error) int {
+	for _, c := range fs.root().exitCodes {
+		if c.match(err) {
+			return c.code
+		}
+	}
+	return ExitFailure
+}
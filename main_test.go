@@ -0,0 +1,107 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMainExitCode(t *testing.T) {
+	fs := New("app", "main").NoPager()
+	fail := fs.Bool(NoShort, "fail", false, "")
+	fs.HandleE(func(context.Context) error {
+		if *fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	fs.Cmd("empty", "no handler")
+
+	for _, c := range []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{nil, ExitOK, ""},
+		{[]string{"--fail"}, ExitFailure, "boom\n"},
+		{[]string{"--unknown"}, ExitUsage, "app: unknown option: --unknown\n"},
+		{[]string{"empty"}, ExitUsage, "app empty - no handler"},
+	} {
+		fs.Reset()
+		stderr := new(bytes.Buffer)
+		if code := fs.main(context.Background(), c.args, stderr); code != c.code || !strings.HasPrefix(stderr.String(), c.stderr) {
+			t.Fatalf("main %q: code %v, stderr %q", c.args, code, stderr)
+		}
+	}
+
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "--fail"); err == nil || err.Error() != "boom" {
+		t.Fatalf("run HandleE: %v", err)
+	}
+}
+
+type notFoundError struct{ name string }
+
+func (e *notFoundError) Error() string { return e.name + " not found" }
+
+func TestExitCode(t *testing.T) {
+	errDenied := errors.New("denied")
+
+	fs := New("app", "exit code")
+	kind := fs.Str(NoShort, "kind", "", "")
+	fs.HandleE(func(context.Context) error {
+		switch *kind {
+		case "denied":
+			return fmt.Errorf("open: %w", errDenied)
+		case "missing":
+			return &notFoundError{name: "x"}
+		}
+		return errors.New("other")
+	})
+	fs.ExitCode(errDenied, 3)
+	ExitCodeAs[*notFoundError](fs, 4)
+
+	for kind, code := range map[string]int{"denied": 3, "missing": 4, "other": ExitFailure} {
+		fs.Reset()
+		if c := fs.main(context.Background(), []string{"--kind", kind}, io.Discard); c != code {
+			t.Fatalf("exit code of %v: %v", kind, c)
+		}
+	}
+}
+
+func TestExiter(t *testing.T) {
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"app", "--fail"}
+
+	fs := New("app", "exiter")
+	fail := fs.Bool(NoShort, "fail", false, "")
+	fs.HandleE(func(context.Context) error {
+		if *fail {
+			return io.EOF
+		}
+		return nil
+	})
+	fs.ExitCode(io.EOF, 3)
+
+	var codes []int
+	fs.SetExiter(ExiterFunc(func(code int) { codes = append(codes, code) }))
+
+	stderr := os.Stderr
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	Main(fs)
+	os.Stderr.Close()
+	os.Stderr = stderr
+
+	os.Args = []string{"app"}
+	fs.Reset()
+	Main(fs)
+	if len(codes) != 2 || codes[0] != 3 || codes[1] != ExitOK {
+		t.Fatalf("exiter codes: %v", codes)
+	}
+}
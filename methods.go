@@ -0,0 +1,151 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// CommandDescriber：Methods注册的对象可实现该接口，为每个方法生成的子命令提供描述。
+type CommandDescriber interface {
+	CommandDesc(method string) string
+}
+
+var (
+	typContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+	typError   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Methods：将svc的导出方法注册为fs的子命令，类似RPC框架暴露服务的方式，减少大量命令的注册代码。
+// 方法签名须为以下形式之一，其它方法忽略：
+//
+//	func(ctx context.Context) [error]
+//	func(ctx context.Context, opts T) [error]
+//	func(ctx context.Context, opts *T) [error]
+//
+// 命令名称为方法名的kebab形式，如ListUsers为list-users；T须为struct，其导出字段注册为命令参数，通过tag设置：
+//
+//	type ListOptions struct {
+//		Limit int      `short:"n" default:"10" desc:"max users"`
+//		Tags  []string `flag:"tag" env:"APP_TAGS"`
+//		Group string   `arg:"group" desc:"user group"`
+//		Debug bool     `flag:"-"`
+//	}
+//
+// flag：长参数名，默认为字段名的kebab形式，"-"表示忽略该字段；short：短参数名；desc：参数描述；
+// default：默认值，格式同命令行参数值；env：绑定的环境变量；arg：注册为位置参数及其名称。
+// 方法返回的错误由Run返回，见HandleE。
+func Methods(fs *FlagSet, svc any) *FlagSet {
+	v := reflect.ValueOf(svc)
+	t := v.Type()
+	describer, _ := svc.(CommandDescriber)
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if m.Name == "CommandDesc" && describer != nil {
+			continue
+		}
+		mt := m.Type // 第一个参数为receiver
+		if mt.NumIn() < 2 || mt.NumIn() > 3 || mt.In(1) != typContext {
+			continue
+		}
+		if mt.NumOut() > 1 || mt.NumOut() == 1 && mt.Out(0) != typError {
+			continue
+		}
+		var opts reflect.Type
+		if mt.NumIn() == 3 {
+			opts = mt.In(2)
+			if opts.Kind() == reflect.Pointer {
+				opts = opts.Elem()
+			}
+			if opts.Kind() != reflect.Struct {
+				continue
+			}
+		}
+
+		var desc string
+		if describer != nil {
+			desc = describer.CommandDesc(m.Name)
+		}
+		cmd := fs.Cmd(kebab(m.Name), desc)
+		fn := v.Method(i)
+		var in reflect.Value
+		if opts != nil {
+			ptr := reflect.New(opts)
+			cmd.structFlags(ptr.Elem())
+			in = ptr
+			if mt.In(2).Kind() != reflect.Pointer {
+				in = ptr.Elem()
+			}
+		}
+		cmd.HandleE(func(ctx context.Context) error {
+			args := []reflect.Value{reflect.ValueOf(ctx)}
+			if in.IsValid() {
+				args = append(args, in)
+			}
+			out := fn.Call(args)
+			if len(out) == 1 && !out[0].IsNil() {
+				return out[0].Interface().(error)
+			}
+			return nil
+		})
+	}
+	return fs
+}
+
+// structFlags：将struct的导出字段注册为参数。
+func (fs *FlagSet) structFlags(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		long := f.Tag.Get("flag")
+		if long == "-" {
+			continue
+		}
+		ptr := v.Field(i).Addr().Interface()
+		desc := f.Tag.Get("desc")
+
+		var p *param
+		if name := f.Tag.Get("arg"); name != "" {
+			fs.ArgVar(ptr, name, nil, desc)
+			p = fs.posArgs[len(fs.posArgs)-1]
+		} else {
+			if long == "" {
+				long = kebab(f.Name)
+			}
+			var short rune
+			if s := []rune(f.Tag.Get("short")); len(s) == 1 {
+				short = s[0]
+			} else if len(s) > 1 {
+				panic(fmt.Errorf("flags: invalid short option: %q", string(s)))
+			}
+			p = fs.addVar(ptr, short, long, nil, desc)
+			if env := f.Tag.Get("env"); env != "" {
+				p.env = env
+			}
+		}
+		if dft, ok := f.Tag.Lookup("default"); ok {
+			fs.specDefault(p, dft)
+		}
+	}
+}
+
+// kebab：将驼峰名称转为kebab形式，如ListUsers为list-users，HTTPServer为http-server。
+func kebab(name string) string {
+	rs := []rune(name)
+	b := new(strings.Builder)
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			if i > 0 && (!unicode.IsUpper(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
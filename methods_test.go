@@ -0,0 +1,86 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type userService struct {
+	listed   ListUsersOptions
+	pinged   bool
+	deleted  string
+	internal bool
+}
+
+type ListUsersOptions struct {
+	Limit   int      `short:"n" default:"10" desc:"max users"`
+	Tags    []string `flag:"tag"`
+	Group   string   `arg:"group" default:"all"`
+	Ignored bool     `flag:"-"`
+	secret  string
+}
+
+func (s *userService) ListUsers(ctx context.Context, opts ListUsersOptions) {
+	s.listed = opts
+}
+
+func (s *userService) Ping(ctx context.Context) error {
+	s.pinged = true
+	return nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, opts *struct{ Name string }) error {
+	s.deleted = opts.Name
+	if opts.Name == "root" {
+		return errors.New("cannot delete root")
+	}
+	return nil
+}
+
+func (s *userService) Internal() { s.internal = true }
+
+func (s *userService) CommandDesc(method string) string {
+	if method == "ListUsers" {
+		return "list users"
+	}
+	return ""
+}
+
+func TestMethods(t *testing.T) {
+	svc := new(userService)
+	fs := Methods(New("app", "methods"), svc)
+
+	if _, err := fs.Run(context.Background(), "list-users", "admin", "--tag", "a", "--tag", "b"); err != nil {
+		t.Fatalf("list users: %v", err)
+	}
+	if o := svc.listed; o.Limit != 10 || o.Group != "admin" || !sliceEqual(o.Tags, "a", "b") {
+		t.Fatalf("list users options: %+v", o)
+	}
+
+	if _, err := fs.Run(context.Background(), "ping"); err != nil || !svc.pinged {
+		t.Fatalf("ping: %v", err)
+	}
+	if _, err := fs.Run(context.Background(), "delete-user", "--name", "root"); err == nil || svc.deleted != "root" {
+		t.Fatalf("delete user: %v", err)
+	}
+	if _, err := fs.Run(context.Background(), "internal"); err == nil || svc.internal {
+		t.Fatalf("internal should not be a command: %v", err)
+	}
+
+	usage := fs.Usage()
+	if !strings.Contains(usage, "  list-users\n    list users") || strings.Contains(usage, "command-desc") {
+		t.Fatalf("usage: %v", usage)
+	}
+	if usage := fs.Command("list-users").Usage(); !strings.Contains(usage, "-n, --limit int (default: 10)\n    max users") ||
+		strings.Contains(usage, "ignored") || strings.Contains(usage, "secret") {
+		t.Fatalf("list users usage: %v", usage)
+	}
+
+	for name, exp := range map[string]string{"ListUsers": "list-users", "HTTPServer": "http-server", "GetID": "get-id", "V2Api": "v2-api"} {
+		if got := kebab(name); got != exp {
+			t.Fatalf("kebab(%v): %v", name, got)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Recorder：命令执行指标的记录接口，可对接Prometheus、StatsD等，见Metrics。
+// command为命令完整名称，如"app remote add"；err为nil表示执行成功，Handler panic时为对应错误。
+type Recorder interface {
+	RecordCommand(command string, duration time.Duration, err error)
+}
+
+// RecorderFunc：函数形式的Recorder。
+type RecorderFunc func(command string, duration time.Duration, err error)
+
+func (f RecorderFunc) RecordCommand(command string, duration time.Duration, err error) {
+	f(command, duration, err)
+}
+
+// Metrics：生成记录命令执行次数、耗时及结果的中间件，每次执行调用一次r.RecordCommand。
+// 执行结果取自HandleE返回的错误；Handler panic时记录后继续panic。
+func Metrics(r Recorder) Middleware {
+	return func(ctx context.Context, next Handler) {
+		inv := getRun(ctx)
+		var command string
+		if inv != nil {
+			command = inv.cmd.fullName()
+		} else if cmd := getCmd(ctx); cmd != nil {
+			command = cmd.fullName()
+		}
+
+		start := time.Now()
+		defer func() {
+			var err error
+			if v := recover(); v != nil {
+				err = fmt.Errorf("panic: %v", v)
+				defer panic(v)
+			} else if inv != nil {
+				err = inv.err
+			}
+			r.RecordCommand(command, time.Since(start), err)
+		}()
+		next(ctx)
+	}
+}
@@ -0,0 +1,50 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	type record struct {
+		command string
+		err     error
+	}
+	var records []record
+	fs := New("app", "metrics")
+	fs.Use(Metrics(RecorderFunc(func(command string, duration time.Duration, err error) {
+		if duration < 0 {
+			t.Fatalf("metrics duration: %v", duration)
+		}
+		records = append(records, record{command, err})
+	})))
+	fs.Cmd("ok", "").Handle(func(context.Context) {})
+	fs.Cmd("fail", "").HandleE(func(context.Context) error { return errors.New("failed") })
+	fs.Cmd("panic", "").Handle(func(context.Context) { panic("boom") })
+
+	fs.Run(context.Background(), "ok")
+	fs.Run(context.Background(), "fail")
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Fatalf("metrics panic: %v", v)
+			}
+		}()
+		fs.Run(context.Background(), "panic")
+	}()
+
+	if len(records) != 3 {
+		t.Fatalf("metrics records: %v", records)
+	}
+	if records[0].command != "app ok" || records[0].err != nil {
+		t.Fatalf("metrics ok: %v", records[0])
+	}
+	if records[1].command != "app fail" || records[1].err == nil || records[1].err.Error() != "failed" {
+		t.Fatalf("metrics fail: %v", records[1])
+	}
+	if records[2].command != "app panic" || records[2].err == nil || records[2].err.Error() != "panic: boom" {
+		t.Fatalf("metrics panic: %v", records[2])
+	}
+}
@@ -0,0 +1,140 @@
+package flags
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NumberFormat：数字参数值可带千位分隔符thousands，浮点数可使用point作为小数点，适用于从表格、报表中粘贴的数字。
+// 如NumberFormat(',', '.')时"1,000,000.5"有效，NumberFormat('.', ',')时"1.000.000,5"有效；thousands为0时不支持千位分隔符。
+// 千位分隔符之间必须为3位数字。对数字slice、map value同样有效，此时需注意分隔符不能与slice/map的分隔符冲突。
+func (f *Flag) NumberFormat(thousands, point rune) *Flag {
+	f.fs.unsealed()
+	leaf := f.p
+	for leaf.elem != nil {
+		leaf = leaf.elem
+	}
+	switch leaf.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		panic(fmt.Errorf("flags: option %v: number format requires number type, got %v", f.p.name(), f.p.typ))
+	}
+	if leaf.typ != leaf.rtyp.String() {
+		panic(fmt.Errorf("flags: option %v: number format requires number type, got %v", f.p.name(), f.p.typ))
+	}
+	if point == 0 || thousands == point || unicode.IsDigit(point) || unicode.IsDigit(thousands) {
+		panic(fmt.Errorf("flags: option %v: invalid number format %q %q", f.p.name(), thousands, point))
+	}
+
+	nf := &numberFormat{thousands: thousands, point: point}
+	for p := f.p; p != nil; p = p.elem {
+		p.numFmt = nf
+	}
+	f.p.set = nil // 经由_parseInts/_parseUints/_parseFloat32/_parseFloat64解析
+	f.fs.touch()
+	return f
+}
+
+type numberFormat struct {
+	thousands rune // 千位分隔符，0表示不支持
+	point     rune // 小数点
+}
+
+// normalize：去除千位分隔符，并将小数点替换为"."。
+func (nf *numberFormat) normalize(s string) (string, error) {
+	intPart, frac, hasPoint := strings.Cut(s, string(nf.point))
+	if nf.thousands != 0 && strings.ContainsRune(intPart, nf.thousands) {
+		groups := strings.Split(intPart, string(nf.thousands))
+		first := strings.TrimLeft(groups[0], "+-")
+		if len(first) == 0 || len(first) > 3 || !allNumbers(first) {
+			return "", fmt.Errorf("invalid number: %q", s)
+		}
+		for i, g := range groups[1:] {
+			digits := g
+			if !hasPoint && i == len(groups)-2 {
+				digits = strings.TrimRightFunc(g, func(r rune) bool { return r < '0' || r > '9' })
+			}
+			if len(digits) != 3 || !allNumbers(digits) {
+				return "", fmt.Errorf("invalid number: %q", s)
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+	if !hasPoint {
+		return intPart, nil
+	}
+	return intPart + "." + frac, nil
+}
+
+func allNumbers(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isNumber(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseInt：解析有符号整数参数值，按需处理NumberFormat及Scaled，支持下划线分隔。
+func (p *param) parseInt(s string) (int64, error) {
+	if p.numFmt != nil {
+		var err error
+		if s, err = p.numFmt.normalize(s); err != nil {
+			return 0, err
+		}
+	}
+	s = digits(s)
+	if p.scaled {
+		return parseScaledInt(s)
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// parseUint：解析无符号整数参数值，按需处理NumberFormat及Scaled，支持下划线分隔。
+func (p *param) parseUint(s string) (uint64, error) {
+	if p.numFmt != nil {
+		var err error
+		if s, err = p.numFmt.normalize(s); err != nil {
+			return 0, err
+		}
+	}
+	s = digits(s)
+	if p.scaled {
+		return parseScaledUint(s)
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// parseFloat：解析浮点数参数值，按需处理NumberFormat，支持下划线分隔。
+func (p *param) parseFloat(s string, bitSize int) (float64, error) {
+	if p.numFmt != nil {
+		var err error
+		if s, err = p.numFmt.normalize(s); err != nil {
+			return 0, err
+		}
+	}
+	return strconv.ParseFloat(digits(s), bitSize)
+}
+
+// digits：去除Go风格的数字分隔下划线，如"1_000_000"。下划线须位于两个数字之间，否则原样返回，由strconv报错。
+func digits(s string) string {
+	if strings.IndexByte(s, '_') < 0 {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' {
+			b = append(b, s[i])
+			continue
+		}
+		if i == 0 || i == len(s)-1 || !isNumber(s[i-1]) || !isNumber(s[i+1]) {
+			return s
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,68 @@
+package flags
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+	fs := New("number", "")
+	count := fs.Int('c', "count", 0, "count")
+	size := fs.Uint(NoShort, "size", 0, "size")
+	price := fs.Float64('p', "price", 0, "price")
+	ratio := fs.Float32('r', "ratio", 0, "ratio")
+	plain := fs.Int(NoShort, "plain", 0, "plain")
+	fs.Flag("count").NumberFormat(',', '.')
+	fs.Flag("size").NumberFormat(',', '.').Scaled()
+	fs.Flag("price").NumberFormat('.', ',')
+	fs.Flag("ratio").NumberFormat(0, ',')
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "-c", "-1,000,000", "--size", "1,024Ki", "-p", "1.234.567,89", "-r", "0,5", "--plain", "7")
+	if err != nil {
+		t.Fatalf("number run: %v", err)
+	}
+	if *count != -1000000 || *size != 1024*1024 || *price != 1234567.89 || *ratio != 0.5 || *plain != 7 {
+		t.Fatalf("number result: %v %v %v %v %v", *count, *size, *price, *ratio, *plain)
+	}
+
+	for _, args := range [][]string{
+		{"-c", "1,00"},
+		{"-c", "1000,000"},
+		{"-c", ",000"},
+		{"-p", "1,234.5"},
+		{"-r", "1.000,5"},
+		{"--plain", "1,000"},
+	} {
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), args...); err == nil {
+			t.Fatalf("number run %q: no err", args)
+		}
+	}
+}
+
+func TestUnderscoreDigits(t *testing.T) {
+	fs := New("underscore", "")
+	limit := fs.Int('l', "limit", 0, "limit")
+	max := fs.Uint16('m', "max", 0, "max")
+	rate := fs.Float64('r', "rate", 0, "rate")
+	ids := Slice[int64](fs, 'i', "ids", nil, "ids")
+	mem := fs.Int64(NoShort, "mem", 0, "memory")
+	fs.Flag("mem").Scaled()
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "-l", "1_000_000", "-m", "65_535", "-r", "1_000.000_1", "-i", "-1_0", "--mem", "1_024Ki")
+	if err != nil {
+		t.Fatalf("underscore run: %v", err)
+	}
+	if *limit != 1000000 || *max != 65535 || *rate != 1000.0001 || !sliceEqual(*ids, -10) || *mem != 1024*1024 {
+		t.Fatalf("underscore result: %v %v %v %v %v", *limit, *max, *rate, *ids, *mem)
+	}
+
+	for _, val := range []string{"_1", "1_", "1__0", "1_.0"} {
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), "-r", val); err == nil {
+			t.Fatalf("underscore run %v: no err", val)
+		}
+	}
+}
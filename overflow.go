@@ -0,0 +1,139 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// OverflowPolicy：整数参数值超出变量类型范围时的处理方式。
+type OverflowPolicy int
+
+const (
+	OverflowError OverflowPolicy = iota // 报错，且不修改变量，默认方式
+	OverflowClamp                       // 取变量类型的最大值或最小值
+	OverflowWrap                        // 按Go类型转换规则截断高位
+)
+
+func (o OverflowPolicy) String() string {
+	switch o {
+	case OverflowError:
+		return "error"
+	case OverflowClamp:
+		return "clamp"
+	case OverflowWrap:
+		return "wrap"
+	}
+	return "OverflowPolicy(" + strconv.Itoa(int(o)) + ")"
+}
+
+// SetOverflow：设置整数参数值溢出时的默认处理方式，可通过Flag.Overflow为单个参数单独设置。
+// 溢出后按clamp或wrap处理时会产生警告，见OnWarning。仅根命令有效。
+func (fs *FlagSet) SetOverflow(policy OverflowPolicy) *FlagSet {
+	fs.unsealed()
+	fs.root().overflow = policy
+	return fs
+}
+
+// Overflow：设置整数参数（包括整数slice、map value）值溢出时的处理方式，见SetOverflow。
+func (f *Flag) Overflow(policy OverflowPolicy) *Flag {
+	f.fs.unsealed()
+	leaf := f.p
+	for leaf.elem != nil {
+		leaf = leaf.elem
+	}
+	if !leaf.isInteger() {
+		panic(fmt.Errorf("flags: option %v: overflow policy requires integer type, got %v", f.p.name(), f.p.typ))
+	}
+	for p := f.p; p != nil; p = p.elem {
+		p.overflow = &policy
+	}
+	f.fs.touch()
+	return f
+}
+
+// isInteger：是否为普通整数参数，time.Duration等自定义解析的类型除外。
+func (p *param) isInteger() bool {
+	switch p.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return p.typ == p.rtyp.String()
+	}
+	return false
+}
+
+// overflowPolicy：参数值溢出时的处理方式。
+func (fs *FlagSet) overflowPolicy(p *param) OverflowPolicy {
+	if p.overflow != nil {
+		return *p.overflow
+	}
+	return fs.root().overflow
+}
+
+// lenientOverflow：整数参数溢出时是否不报错，此时需经由_parseInts/_parseUints解析。
+func (fs *FlagSet) lenientOverflow(p *param) bool {
+	return p.isInteger() && fs.overflowPolicy(p) != OverflowError
+}
+
+// setInt：按溢出处理方式将i赋值给有符号整数变量val。
+func (fs *FlagSet) setInt(val reflect.Value, arg string, p *param, s string, i int64, err error) error {
+	policy := fs.overflowPolicy(p)
+	if err != nil {
+		// strconv在超出int64范围时返回最大/最小值
+		if policy != OverflowClamp || !errors.Is(err, strconv.ErrRange) {
+			return fs._parseParamErr(arg, err)
+		}
+	} else if !val.OverflowInt(i) {
+		val.SetInt(i)
+		return nil
+	}
+
+	switch policy {
+	case OverflowClamp:
+		bits := val.Type().Bits()
+		max := int64(math.MaxInt64 >> (64 - bits))
+		min := -max - 1
+		if i > max {
+			i = max
+		} else if i < min {
+			i = min
+		}
+		val.SetInt(i)
+	case OverflowWrap:
+		val.SetInt(i)
+	default:
+		return fs._parseParamErr(arg, fmt.Errorf("cannot set %v to an %v, overflowed", i, p.typ))
+	}
+	fs.warn(arg, "value %v overflows %v, %v to %v", s, p.typ, policy, val.Int())
+	return nil
+}
+
+// setUint：按溢出处理方式将i赋值给无符号整数变量val。
+func (fs *FlagSet) setUint(val reflect.Value, arg string, p *param, s string, i uint64, err error) error {
+	policy := fs.overflowPolicy(p)
+	if err != nil {
+		if policy != OverflowClamp || !errors.Is(err, strconv.ErrRange) {
+			return fs._parseParamErr(arg, err)
+		}
+	} else if !val.OverflowUint(i) {
+		val.SetUint(i)
+		return nil
+	}
+
+	switch policy {
+	case OverflowClamp:
+		max := uint64(math.MaxUint64 >> (64 - val.Type().Bits()))
+		if i > max {
+			i = max
+		}
+		val.SetUint(i)
+	case OverflowWrap:
+		val.SetUint(i)
+	default:
+		return fs._parseParamErr(arg, fmt.Errorf("cannot set %v to an %v, overflowed", i, p.typ))
+	}
+	fs.warn(arg, "value %v overflows %v, %v to %v", s, p.typ, policy, val.Uint())
+	return nil
+}
@@ -0,0 +1,59 @@
+package flags
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOverflow(t *testing.T) {
+	fs := New("overflow", "")
+	strict := fs.Int8('s', "strict", 0, "strict")
+	clamp := fs.Int8('c', "clamp", 0, "clamp")
+	wrap := fs.Uint8('w', "wrap", 0, "wrap")
+	big := fs.Int64('b', "big", 0, "big")
+	ids := Slice[uint16](fs, 'i', "ids", nil, "ids")
+	fs.Flag("clamp").Overflow(OverflowClamp)
+	fs.Flag("wrap").Overflow(OverflowWrap)
+	fs.Flag("big").Overflow(OverflowClamp)
+	fs.Flag("ids").Overflow(OverflowClamp)
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "-c", "200", "-w", "257", "-b", "99999999999999999999", "-i", "70000", "-i", "1")
+	if err != nil {
+		t.Fatalf("overflow run: %v", err)
+	}
+	if *clamp != 127 || *wrap != 1 || *big != 1<<63-1 || !sliceEqual(*ids, 65535, 1) {
+		t.Fatalf("overflow result: %v %v %v %v", *clamp, *wrap, *big, *ids)
+	}
+	if ws := fs.Warnings(); len(ws) != 4 || ws[0].String() != "overflow: option -c: value 200 overflows int8, clamp to 127" {
+		t.Fatalf("overflow warnings: %v", ws)
+	}
+
+	fs.Reset()
+	*strict = 3
+	if _, err = fs.Run(context.Background(), "-s", "128"); err == nil {
+		t.Fatalf("overflow strict: no err")
+	}
+	if *strict != 3 {
+		t.Fatalf("overflow strict modified value: %v", *strict)
+	}
+
+	// FlagSet default
+	fs.SetOverflow(OverflowClamp)
+	fs.Reset()
+	if _, err = fs.Run(context.Background(), "-s", "-1000", "-w", "300"); err != nil {
+		t.Fatalf("overflow default run: %v", err)
+	}
+	if *strict != -128 || *wrap != 44 {
+		t.Fatalf("overflow default result: %v %v", *strict, *wrap)
+	}
+
+	// reflect path does not mutate on error
+	var n int16 = 5
+	fs2 := New("overflow", "")
+	fs2.AnyVar(&n, 'n', "n", int16(0), "n")
+	fs2.Handle(func(context.Context) {})
+	if _, err = fs2.Run(context.Background(), "-n", "40000"); err == nil || n != 5 {
+		t.Fatalf("overflow reflect: %v %v", err, n)
+	}
+}
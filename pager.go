@@ -0,0 +1,71 @@
+package flags
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// NoPager：关闭帮助信息分页，PrintUsage将直接输出到stdout。
+// 也可通过环境变量PAGER设置为空字符串或cat关闭分页。
+func (fs *FlagSet) NoPager() *FlagSet {
+	fs.unsealed()
+	fs.root().nopager = true
+	return fs
+}
+
+// PrintUsage：打印帮助信息到stdout，见SetStdio。
+// 当stdout为终端且帮助信息超出终端高度时，通过$PAGER（默认为less）分页展示，类似git。
+func (fs *FlagSet) PrintUsage(usage string) error {
+	stdout := fs.Stdout()
+	if f, ok := stdout.(*os.File); ok && !fs.root().nopager {
+		if pager := pagerCmd(); pager != nil && needPager(f, usage) {
+			pager.Stdin = strings.NewReader(usage + "\n")
+			pager.Stdout = f
+			pager.Stderr = fs.Stderr()
+			if err := pager.Run(); err == nil {
+				return nil
+			}
+		}
+	}
+	_, err := fmt.Fprintln(stdout, usage)
+	return err
+}
+
+func (fs *FlagSet) root() *FlagSet {
+	f := fs
+	for f.parent != nil {
+		f = f.parent
+	}
+	return f
+}
+
+func pagerCmd() *exec.Cmd {
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = "less"
+	}
+	args := strings.Fields(pager)
+	if len(args) == 0 || args[0] == "cat" {
+		return nil
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return nil
+	}
+
+	cmd := exec.Command(path, args[1:]...)
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	return cmd
+}
+
+func needPager(f *os.File, usage string) bool {
+	height, ok := termHeight(f)
+	if !ok {
+		return false
+	}
+	return strings.Count(usage, "\n")+1 > height
+}
@@ -0,0 +1,22 @@
+//go:build !linux && !darwin
+
+package flags
+
+import (
+	"os"
+	"strconv"
+)
+
+// termHeight：获取终端高度，f不是终端时返回false。
+// 非linux/darwin平台无法直接获取终端大小，以环境变量LINES为准。
+func termHeight(f *os.File) (int, bool) {
+	st, err := f.Stat()
+	if err != nil || st.Mode()&os.ModeCharDevice == 0 {
+		return 0, false
+	}
+	height, err := strconv.Atoi(os.Getenv("LINES"))
+	if err != nil || height <= 0 {
+		return 0, false
+	}
+	return height, true
+}
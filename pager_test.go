@@ -0,0 +1,77 @@
+package flags
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrintUsage(t *testing.T) {
+	fs := New("app", "")
+
+	// injected writers are not terminals and never go through the pager
+	stdout := new(bytes.Buffer)
+	fs.SetStdio(nil, stdout, nil)
+	if err := fs.PrintUsage("a\nb"); err != nil || stdout.String() != "a\nb\n" {
+		t.Fatalf("print usage: %v, %q", err, stdout)
+	}
+
+	// a regular file is not a terminal either
+	f, err := os.Create(filepath.Join(t.TempDir(), "usage.txt"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	defer f.Close()
+	fs.SetStdio(nil, f, nil)
+	if err = fs.PrintUsage("usage"); err != nil {
+		t.Fatalf("print usage to file: %v", err)
+	}
+	if b, _ := os.ReadFile(f.Name()); string(b) != "usage\n" {
+		t.Fatalf("usage file: %q", b)
+	}
+}
+
+func TestNeedPager(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "usage.txt"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	defer f.Close()
+	if needPager(f, string(bytes.Repeat([]byte("line\n"), 1000))) {
+		t.Fatalf("need pager for a regular file")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if needPager(w, string(bytes.Repeat([]byte("line\n"), 1000))) {
+		t.Fatalf("need pager for a pipe")
+	}
+}
+
+func TestPagerCmd(t *testing.T) {
+	for _, pager := range []string{"", "  ", "cat", "cat -v", "no-such-pager-for-flags-test"} {
+		t.Setenv("PAGER", pager)
+		if cmd := pagerCmd(); cmd != nil {
+			t.Fatalf("pager %q: %v", pager, cmd.Args)
+		}
+	}
+
+	t.Setenv("PAGER", "sh -c true")
+	t.Setenv("LESS", "")
+	os.Unsetenv("LESS")
+	cmd := pagerCmd()
+	if cmd == nil || !sliceEqual(cmd.Args[1:], "-c", "true") || !strings.Contains(strings.Join(cmd.Env, "\n"), "\nLESS=FRX") {
+		t.Fatalf("pager cmd: %v", cmd)
+	}
+
+	t.Setenv("LESS", "R")
+	if cmd = pagerCmd(); cmd == nil || cmd.Env != nil {
+		t.Fatalf("pager cmd with LESS: %v", cmd)
+	}
+}
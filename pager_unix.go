@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package flags
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// termHeight：获取终端高度，f不是终端时返回false。
+func termHeight(f *os.File) (int, bool) {
+	var ws struct {
+		row, col, xpixel, ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(),
+		uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 || ws.row == 0 {
+		return 0, false
+	}
+	return int(ws.row), true
+}
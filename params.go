@@ -0,0 +1,84 @@
+package flags
+
+import "math"
+
+// 子命令不复制父命令的参数列表，而是记录继承的参数个数inherit，
+// 可见参数为父命令可见参数的前inherit个，加上当前命令自己的参数列表params。
+// 父命令之后注册的参数追加在其列表末尾，不影响已创建的子命令；
+// 只有覆盖同名参数（见addVar）会原地修改列表，此时先将继承者的参数列表物化为完整副本。
+
+// numParams：当前命令可见的参数个数。
+func (fs *FlagSet) numParams() int {
+	return fs.inherit + len(fs.params)
+}
+
+// eachParam：按注册顺序遍历当前命令可见的参数，包括继承自父命令的参数，fn返回false时停止遍历。
+func (fs *FlagSet) eachParam(fn func(p *param) bool) {
+	fs.rangeParams(math.MaxInt, fn)
+}
+
+// rangeParams：遍历前n个可见参数，返回已遍历的个数，以及是否遍历完成（fn未返回false）。
+func (fs *FlagSet) rangeParams(n int, fn func(p *param) bool) (int, bool) {
+	visited := 0
+	if fs.inherit > 0 {
+		var ok bool
+		if visited, ok = fs.parent.rangeParams(min(n, fs.inherit), fn); !ok {
+			return visited, false
+		}
+	}
+	for _, p := range fs.params {
+		if visited >= n {
+			break
+		}
+		visited++
+		if !fn(p) {
+			return visited, false
+		}
+	}
+	return visited, true
+}
+
+// allParams：当前命令可见的参数列表，用于Usage等非热点路径。返回值可安全地append。
+func (fs *FlagSet) allParams() []*param {
+	if fs.inherit == 0 {
+		return fs.params[:len(fs.params):len(fs.params)]
+	}
+	params := make([]*param, 0, fs.numParams())
+	fs.eachParam(func(p *param) bool {
+		params = append(params, p)
+		return true
+	})
+	return params
+}
+
+// findParam：查找第一个满足match的可见参数。
+func (fs *FlagSet) findParam(match func(p *param) bool) *param {
+	var found *param
+	fs.eachParam(func(p *param) bool {
+		if match(p) {
+			found = p
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// bequeath：子命令child继承当前命令已注册的全部参数。
+func (fs *FlagSet) bequeath(child *FlagSet) {
+	child.inherit = fs.numParams()
+	if child.inherit > 0 {
+		fs.heirs = append(fs.heirs, child)
+	}
+}
+
+// materialize：将当前命令及其继承者的参数列表物化为完整副本，之后当前命令的参数列表可以原地修改。
+func (fs *FlagSet) materialize() {
+	for _, heir := range fs.heirs {
+		heir.params = heir.allParams()
+		heir.inherit = 0
+	}
+	fs.heirs = nil
+	fs.params = fs.allParams()
+	fs.inherit = 0
+}
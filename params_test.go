@@ -0,0 +1,55 @@
+package flags
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSharedParams(t *testing.T) {
+	fs := New("app", "")
+	config := fs.Str('c', "config", "app.cfg", "")
+	level := fs.Int('l', "level", 1, "")
+	s := fs.Stmt()
+	build := s.Cmd("build", "")
+	build.Handle(func(context.Context) {})
+	fs.Bool('v', "verbose", false, "") // registered after build: not inherited
+
+	// children share the parent's params instead of copying them
+	if len(s.params) != 0 || len(build.params) != 0 || build.numParams() != 2 {
+		t.Fatalf("build params: %v, inherit: %v", len(build.params), build.inherit)
+	}
+	if build.lookup("config") == nil || build.lookup("verbose") != nil {
+		t.Fatalf("build lookup")
+	}
+
+	// shadowing in a parent after its children were created must not affect them
+	mid := fs.Cmd("mid", "")
+	leaf := mid.Cmd("leaf", "")
+	mid.Str('l', "level", "high", "")
+	if leaf.lookup("level").ptr != any(level) || mid.lookup("level").ptr == any(level) {
+		t.Fatalf("leaf level changed by parent shadowing")
+	}
+
+	// shadowing in the child
+	test := fs.Cmd("test", "")
+	testConfig := test.Str('c', "config", "test.cfg", "")
+	test.Handle(func(context.Context) {})
+	if test.numParams() != 3 || fs.lookup("config").ptr != any(config) {
+		t.Fatalf("test params: %v", test.numParams())
+	}
+
+	if _, err := fs.Run(context.Background(), "-l", "2", "test", "-c", "x.cfg"); err != nil {
+		t.Fatalf("run test: %v", err)
+	}
+	if *level != 2 || *testConfig != "x.cfg" || *config != "app.cfg" {
+		t.Fatalf("level: %v, test config: %v, config: %v", *level, *testConfig, *config)
+	}
+
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "build", "-l", "3", "-c", "b.cfg"); err != nil {
+		t.Fatalf("run build: %v", err)
+	}
+	if *level != 3 || *config != "b.cfg" {
+		t.Fatalf("build level: %v, config: %v", *level, *config)
+	}
+}
@@ -0,0 +1,85 @@
+package flags
+
+import "reflect"
+
+// MatchedCommand：Parse匹配到的命令。
+type MatchedCommand struct {
+	Command *FlagSet // 匹配到的命令
+	Path    string   // 命令完整名称，如"app remote add"
+	Args    []string // 位置参数，以及未识别的剩余参数
+}
+
+// Parse：只解析参数，不要求也不执行Handler，便于将本库作为纯解析器嵌入其它框架。
+// 与Run不同，无法识别的子命令不会报错，而是与位置参数一起放入MatchedCommand.Args。
+func (fs *FlagSet) Parse(args ...string) (*MatchedCommand, error) {
+	fs.root().warnings = nil
+	args, err := fs.preprocess(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = fs.checkArgsLimit(args); err != nil {
+		return nil, err
+	}
+	if err = fs.loadConfig(); err != nil {
+		return nil, err
+	}
+	a := newArgs(args...)
+	a.leftover = true
+	f, err := fs._parse(a)
+	if err != nil {
+		return nil, err
+	}
+	return &MatchedCommand{Command: f, Path: f.fullName(), Args: a.positional}, nil
+}
+
+// ParseValues：同Parse，但不改变已绑定的参数变量，适用于校验不可信输入、模糊测试等需要无副作用解析的场景。
+// 解析前保存各参数的值并置为零值，解析后恢复，匹配到的命令的各参数值以map返回，key为参数名称，如"--output"。
+// 注意OnUnknownFlag等回调仍会被调用，FromFile等参数仍会读取文件。
+func (fs *FlagSet) ParseValues(args ...string) (*MatchedCommand, map[string]any, error) {
+	type saved struct {
+		p      *param
+		val    reflect.Value
+		parsed bool
+		source Source
+	}
+	var states []saved
+	seen := make(map[*param]bool)
+	var walk func(f *FlagSet)
+	walk = func(f *FlagSet) {
+		for _, p := range append(f.allParams(), f.allArgs()...) {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			v := reflect.ValueOf(p.ptr).Elem()
+			s := saved{p: p, val: reflect.New(v.Type()).Elem(), parsed: p.parsed, source: p.source}
+			s.val.Set(v)
+			states = append(states, s)
+
+			v.SetZero()
+			p.parsed = false
+			p.source = SourceDefault
+		}
+		for _, cmd := range f.cmds {
+			walk(cmd)
+		}
+	}
+	walk(fs)
+	defer func() {
+		for _, s := range states {
+			reflect.ValueOf(s.p.ptr).Elem().Set(s.val)
+			s.p.parsed = s.parsed
+			s.p.source = s.source
+		}
+	}()
+
+	m, err := fs.Parse(args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make(map[string]any)
+	for _, p := range append(m.Command.allParams(), m.Command.allArgs()...) {
+		values[p.name()] = reflect.ValueOf(p.ptr).Elem().Interface()
+	}
+	return m, values, nil
+}
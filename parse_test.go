@@ -0,0 +1,66 @@
+package flags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	fs := New("app", "parse only")
+	verbose := fs.Bool('v', "verbose", false, "")
+	remote := fs.Cmd("remote", "")
+	add := remote.Cmd("add", "")
+	name := Arg[string](add, "name", "", "")
+
+	m, err := fs.Parse("-v", "remote", "add", "origin", "git@x", "-v")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if m.Command != add || m.Path != "app remote add" || !sliceEqual(m.Args, "origin", "git@x") {
+		t.Fatalf("matched: %+v", m)
+	}
+	if !*verbose || *name != "origin" {
+		t.Fatalf("verbose: %v, name: %q", *verbose, *name)
+	}
+
+	fs.Reset()
+	if m, err = fs.Parse("x", "y"); err != nil || m.Command != fs || !sliceEqual(m.Args, "x", "y") {
+		t.Fatalf("leftover: %+v, %v", m, err)
+	}
+	if _, err = fs.Parse("--unknown"); err == nil {
+		t.Fatalf("unknown option should fail")
+	}
+	if _, err = fs.Parse("-h"); !errors.Is(err, ErrHelp) {
+		t.Fatalf("help: %v", err)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	fs := New("app", "")
+	output := fs.Str('o', "output", "a.out", "")
+	tags := Slice[string](fs, 't', "tags", nil, "")
+	build := fs.Cmd("build", "")
+	target := Arg[string](build, "target", "all", "")
+
+	*output = "keep"
+	m, values, err := fs.ParseValues("-t", "x", "-t", "y", "build", "-o", "b.out", "lib")
+	if err != nil {
+		t.Fatalf("parse values: %v", err)
+	}
+	if m.Path != "app build" {
+		t.Fatalf("matched: %+v", m)
+	}
+	if values["--output"] != "b.out" || !sliceEqual(values["--tags"].([]string), "x", "y") || values["target"] != "lib" {
+		t.Fatalf("values: %v", values)
+	}
+	if *output != "keep" || *tags != nil || *target != "" {
+		t.Fatalf("bound variables changed: %q, %q, %q", *output, *tags, *target)
+	}
+	if fs.Flag("output").Source() != SourceDefault {
+		t.Fatalf("source changed: %v", fs.Flag("output").Source())
+	}
+
+	if _, _, err = fs.ParseValues("--unknown"); err == nil {
+		t.Fatalf("parse values unknown option should fail")
+	}
+}
@@ -0,0 +1,228 @@
+package flags
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+func (fs *FlagSet) Path(short rune, long string, dft string, desc string) *string {
+	ptr := new(string)
+	fs.PathVar(ptr, short, long, dft, desc)
+	return ptr
+}
+
+// PathVar：文件路径参数，解析时将开头的"~"、"~user"展开为对应用户的home目录，
+// 因为shell不会展开"--flag=~/x"形式中的"~"。默认值同样会被展开，Usage中显示原值。
+func (fs *FlagSet) PathVar(ptr *string, short rune, long string, dft string, desc string) {
+	fs.pathVar(ptr, short, long, dft, desc)
+}
+
+func (fs *FlagSet) pathVar(ptr *string, short rune, long string, dft string, desc string) *param {
+	p := addTyped(fs, ptr, short, long, dft, desc, expandHome)
+	p.typ = "path"
+	p.path = new(pathCheck)
+	if p.setDft != nil {
+		p.setDft = func(ptr any) {
+			if path, err := expandHome(dft); err == nil {
+				*ptr.(*string) = path
+			} else {
+				*ptr.(*string) = dft
+			}
+		}
+	}
+	return p
+}
+
+// expandHome：展开路径开头的"~"或"~user"。
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+
+	name, rest := path[1:], ""
+	if i := strings.IndexAny(name, `/`+string(filepath.Separator)); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+
+	var home string
+	if name == "" {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("expand %q: %w", path, err)
+		}
+		home = dir
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("expand %q: %w", path, err)
+		}
+		home = u.HomeDir
+	}
+	return home + rest, nil
+}
+
+func (fs *FlagSet) Dir(short rune, long string, dft string, desc string) *string {
+	ptr := new(string)
+	fs.DirVar(ptr, short, long, dft, desc)
+	return ptr
+}
+
+// DirVar：目录参数，同PathVar，执行Handler前若路径存在则必须为目录，
+// 可通过Flag.MustExist要求目录存在，或通过Flag.CreateIfMissing在目录不存在时创建。
+func (fs *FlagSet) DirVar(ptr *string, short rune, long string, dft string, desc string) {
+	p := fs.pathVar(ptr, short, long, dft, desc)
+	p.typ = "dir"
+	p.path.dir = true
+}
+
+// pathCheck：路径参数解析完成后的检查项。
+type pathCheck struct {
+	dir       bool // 是否为目录参数
+	mustExist bool // 路径必须存在，见Flag.MustExist
+	create    bool // 目录不存在时创建，见Flag.CreateIfMissing
+
+	readable   bool // 必须可读，见Flag.Readable
+	writable   bool // 必须可写，见Flag.Writable
+	executable bool // 必须可执行，见Flag.Executable
+}
+
+// MustExist：要求Path、Dir参数指定的路径存在，Dir参数还要求其为目录。参数值为空时不检查。
+func (f *Flag) MustExist() *Flag {
+	f.fs.unsealed()
+	f.pathCheck().mustExist = true
+	f.fs.touch()
+	return f
+}
+
+// CreateIfMissing：Dir参数指定的目录不存在时，在执行Handler前创建（含父目录）。参数值为空时不创建。
+// Parse、ParseValues及DryRun不执行Handler，因此不会创建目录。
+func (f *Flag) CreateIfMissing() *Flag {
+	f.fs.unsealed()
+	c := f.pathCheck()
+	if !c.dir {
+		panic(fmt.Errorf("flags: option %v: create if missing requires dir type, got %v", f.p.name(), f.p.typ))
+	}
+	c.create = true
+	f.fs.touch()
+	return f
+}
+
+// Readable：要求Path、Dir参数指定的路径存在且可读，在执行Handler前检查，权限问题作为参数错误报告。参数值为空时不检查。
+func (f *Flag) Readable() *Flag {
+	f.fs.unsealed()
+	f.pathCheck().readable = true
+	f.fs.touch()
+	return f
+}
+
+// Writable：要求Path、Dir参数指定的路径可写。路径不存在时要求其所在目录可写，以便创建文件。参数值为空时不检查。
+func (f *Flag) Writable() *Flag {
+	f.fs.unsealed()
+	f.pathCheck().writable = true
+	f.fs.touch()
+	return f
+}
+
+// Executable：要求Path参数指定的文件存在且有可执行权限，Dir参数则要求目录可进入。参数值为空时不检查。
+func (f *Flag) Executable() *Flag {
+	f.fs.unsealed()
+	f.pathCheck().executable = true
+	f.fs.touch()
+	return f
+}
+
+func (f *Flag) pathCheck() *pathCheck {
+	if f.p.path == nil {
+		panic(fmt.Errorf("flags: option %v: requires path or dir type, got %v", f.p.name(), f.p.typ))
+	}
+	return f.p.path
+}
+
+// checkPaths：执行Handler前检查命令的路径参数最终值，并按需创建目录。
+// 不在解析时检查，以免Parse、ParseValues、DryRun及被覆盖的中间值产生副作用。
+func (fs *FlagSet) checkPaths() error {
+	for _, p := range fs.allParams() {
+		if p.path == nil {
+			continue
+		}
+		path := *p.ptr.(*string)
+		if path == "" {
+			continue
+		}
+		if err := p.path.check(path); err != nil {
+			return fmt.Errorf("%v: option %v: %w", fs.fullName(), p.name(), err)
+		}
+	}
+	return nil
+}
+
+func (c *pathCheck) check(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if c.dir && c.create {
+			if err = os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			return c.checkPerm(path, true)
+		}
+		if c.mustExist || c.readable || c.executable {
+			return err
+		}
+		if c.writable {
+			return checkWritable(filepath.Dir(path), true)
+		}
+		return nil
+	}
+	if c.dir && !fi.IsDir() {
+		return fmt.Errorf("%v: not a directory", path)
+	}
+	return c.checkPerm(path, fi.IsDir())
+}
+
+func (c *pathCheck) checkPerm(path string, isDir bool) error {
+	if c.readable {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		f.Close()
+	}
+	if c.writable {
+		if err := checkWritable(path, isDir); err != nil {
+			return err
+		}
+	}
+	if c.executable {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if fi.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("%v: permission denied: not executable", path)
+		}
+	}
+	return nil
+}
+
+// checkWritable：检查文件是否可写，或目录中是否可创建文件。
+func checkWritable(path string, isDir bool) error {
+	if !isDir {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+	f, err := os.CreateTemp(path, ".flags-writable-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
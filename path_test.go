@@ -0,0 +1,203 @@
+package flags
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPathTilde(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	fs := New("path", "")
+	config := fs.Path('c', "config", "~/.app/config", "config file")
+	out := fs.Path('o', "output", "", "output file")
+	fs.Handle(func(context.Context) {})
+
+	cases := []struct {
+		args   []string
+		config string
+		out    string
+	}{
+		{nil, home + "/.app/config", ""},
+		{[]string{"--config=~/app.cfg"}, home + "/app.cfg", ""},
+		{[]string{"-c", "~"}, home, ""},
+		{[]string{"-o", "/tmp/~x", "-c", "a~b"}, "a~b", "/tmp/~x"},
+	}
+	for _, c := range cases {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), c.args...)
+		if err != nil {
+			t.Fatalf("path run %q: %v", c.args, err)
+		}
+		if *config != c.config || *out != c.out {
+			t.Fatalf("path run %q result: %v %v", c.args, *config, *out)
+		}
+	}
+
+	if u, err := user.Current(); err == nil && u.Username != "" && !strings.ContainsAny(u.Username, `/\`) {
+		fs.Reset()
+		if _, err = fs.Run(context.Background(), "-o", "~"+u.Username+"/x"); err != nil {
+			t.Fatalf("path run ~user: %v", err)
+		}
+		if *out != u.HomeDir+"/x" {
+			t.Fatalf("path run ~user result: %v", *out)
+		}
+	}
+
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "-o", "~no-such-user-flags/x"); err == nil {
+		t.Fatalf("path run unknown user: no err")
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, `-c, --config path (default: "~/.app/config")`) {
+		t.Fatalf("path usage: %v", usage)
+	}
+}
+
+func TestDir(t *testing.T) {
+	tmp := t.TempDir()
+	file := filepath.Join(tmp, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	fs := New("dir", "")
+	input := fs.Dir('i', "input", "", "input directory")
+	output := fs.Dir('o', "output", "", "output directory")
+	cache := fs.Dir(NoShort, "cache", "", "cache directory")
+	config := fs.Path('c', "config", "", "config file")
+	fs.Flag("input").MustExist()
+	fs.Flag("output").CreateIfMissing()
+	fs.Flag("config").MustExist()
+	var ran bool
+	fs.Handle(func(context.Context) { ran = true })
+
+	out := filepath.Join(tmp, "a", "b")
+	_, err := fs.Run(context.Background(), "-i", tmp, "-o", out, "-c", file, "--cache", filepath.Join(tmp, "missing"))
+	if err != nil || !ran {
+		t.Fatalf("dir run: %v", err)
+	}
+	if fi, err := os.Stat(out); err != nil || !fi.IsDir() {
+		t.Fatalf("dir not created: %v", err)
+	}
+	if *input != tmp || *output != out || *cache == "" || *config != file {
+		t.Fatalf("dir result: %v %v %v %v", *input, *output, *cache, *config)
+	}
+
+	for _, args := range [][]string{
+		{"-i", filepath.Join(tmp, "missing")},
+		{"-i", file},
+		{"-o", filepath.Join(file, "x")},
+		{"--cache", file},
+		{"-c", filepath.Join(tmp, "missing")},
+	} {
+		fs.Reset()
+		ran = false
+		_, err := fs.Run(context.Background(), args...)
+		if err == nil || ran {
+			t.Fatalf("dir run %q: no err", args)
+		}
+		if !strings.Contains(err.Error(), "dir: option ") {
+			t.Fatalf("dir run %q err: %v", args, err)
+		}
+	}
+}
+
+func TestDirCreatedBeforeHandler(t *testing.T) {
+	tmp := t.TempDir()
+	dft, other := filepath.Join(tmp, "dft"), filepath.Join(tmp, "other")
+	fs := New("app", "")
+	fs.Dir('o', "out", dft, "")
+	fs.Flag("out").CreateIfMissing()
+	build := fs.Cmd("build", "")
+	var ran bool
+	build.Handle(func(context.Context) { ran = true })
+
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	// parsing only never creates directories
+	if _, err := fs.Parse("build"); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, _, err := fs.ParseValues("build"); err != nil {
+		t.Fatalf("parse values: %v", err)
+	}
+	fs.DryRun(io.Discard)
+	if _, err := fs.Run(context.Background(), "build"); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	fs.DryRun(nil)
+	if exists(dft) {
+		t.Fatalf("dir created without running the handler")
+	}
+
+	// only the final value is created
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "build", "-o", other); err != nil || !ran {
+		t.Fatalf("run: %v", err)
+	}
+	if exists(dft) || !exists(other) {
+		t.Fatalf("default created: %v, other created: %v", exists(dft), exists(other))
+	}
+
+	// check errors are reported as option errors with usage
+	file := filepath.Join(tmp, "file")
+	os.WriteFile(file, nil, 0644)
+	fs.Reset()
+	ran = false
+	usage, err := fs.Run(context.Background(), "build", "-o", file)
+	if err == nil || ran || usage == "" || !strings.Contains(err.Error(), "app build: option --out: ") {
+		t.Fatalf("check error: %v, usage: %q", err, usage)
+	}
+}
+
+func TestPathPermission(t *testing.T) {
+	tmp := t.TempDir()
+	script := filepath.Join(tmp, "run.sh")
+	data := filepath.Join(tmp, "data.txt")
+	os.WriteFile(script, []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(data, []byte("data"), 0644)
+
+	fs := New("perm", "")
+	fs.Path('i', "input", "", "input file")
+	fs.Path('o', "output", "", "output file")
+	fs.Path('x', "exec", "", "executable")
+	fs.Dir('d', "dir", "", "work directory")
+	fs.Flag("input").Readable()
+	fs.Flag("output").Writable()
+	fs.Flag("exec").Executable()
+	fs.Flag("dir").Readable().Writable().Executable()
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "-i", data, "-o", filepath.Join(tmp, "new.txt"), "-x", script, "-d", tmp)
+	if err != nil {
+		t.Fatalf("perm run: %v", err)
+	}
+
+	cases := [][]string{
+		{"-i", filepath.Join(tmp, "missing")},
+		{"-x", data},
+		{"-x", filepath.Join(tmp, "missing")},
+		{"-o", filepath.Join(tmp, "missing", "out.txt")},
+	}
+	if os.Getuid() != 0 { // root bypasses permission bits
+		locked := filepath.Join(tmp, "locked")
+		os.WriteFile(locked, nil, 0)
+		cases = append(cases, []string{"-i", locked}, []string{"-o", locked})
+	}
+	for _, args := range cases {
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), args...); err == nil {
+			t.Fatalf("perm run %q: no err", args)
+		}
+	}
+}
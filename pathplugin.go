@@ -0,0 +1,89 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// PathPlugins：开启外部插件子命令，类似git/kubectl插件机制。
+// 子命令未匹配时，在PATH中查找名为"<app>-<cmd>"的可执行文件（多级子命令为"<app>-<sub>-<cmd>"），
+// 找到则将剩余参数原样传给该程序执行。开启后Usage中会列出发现的插件。
+func (fs *FlagSet) PathPlugins() *FlagSet {
+	fs.unsealed()
+	fs.root().pathPlugins = true
+	return fs
+}
+
+func (fs *FlagSet) pluginPrefix() string {
+	return strings.ReplaceAll(fs.fullName(), " ", "-") + "-"
+}
+
+// lookPlugin：查找名为name的外部插件，找到则返回执行插件的子命令，并消耗剩余所有参数。
+func (fs *FlagSet) lookPlugin(args *arguments, name string) *FlagSet {
+	if !fs.root().pathPlugins || strings.ContainsAny(name, `/\`) {
+		return nil
+	}
+	path, err := exec.LookPath(fs.pluginPrefix() + name)
+	if err != nil {
+		return nil
+	}
+
+	rest := make([]string, len(args.args)-args.idx)
+	copy(rest, args.args[args.idx:])
+	args.idx = len(args.args)
+	return &FlagSet{
+		name:       name,
+		parent:     fs,
+		plugin:     path,
+		pluginArgs: rest,
+	}
+}
+
+func (fs *FlagSet) runPlugin(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, fs.plugin, fs.pluginArgs...)
+	cmd.Stdin = fs.Stdin()
+	cmd.Stdout = fs.Stdout()
+	cmd.Stderr = fs.Stderr()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("flags: plugin %v: %w", fs.fullName(), err)
+	}
+	return nil
+}
+
+// discoverPlugins：在PATH中查找fs的所有外部插件名称。
+func (fs *FlagSet) discoverPlugins() []string {
+	prefix := fs.pluginPrefix()
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			} else if info, err := entry.Info(); err != nil || info.Mode()&0111 == 0 {
+				continue
+			}
+			name = strings.TrimPrefix(name, prefix)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,41 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPathPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugin")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := "#!/bin/sh\necho \"$@\" > " + out + "\n"
+	err := os.WriteFile(filepath.Join(dir, "app-hello"), []byte(script), 0755)
+	if err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	t.Setenv("PATH", dir+string(filepath.ListSeparator)+os.Getenv("PATH"))
+
+	fs := New("app", "").PathPlugins()
+	fs.Cmd("sub", "")
+
+	_, err = fs.Run(context.Background(), "hello", "-x", "world")
+	if err != nil {
+		t.Fatalf("plugin run: %v", err)
+	}
+	b, _ := os.ReadFile(out)
+	if got := strings.TrimSpace(string(b)); got != "-x world" {
+		t.Fatalf("plugin args: %q", got)
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, "Plugins:\n  hello") {
+		t.Fatalf("plugin usage: %v", usage)
+	}
+}
@@ -0,0 +1,41 @@
+package flags
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func (fs *FlagSet) Percent(short rune, long string, dft float64, desc string) *float64 {
+	ptr := new(float64)
+	fs.PercentVar(ptr, short, long, dft, desc)
+	return ptr
+}
+
+// PercentVar：百分比参数，支持"15%"及"0.15"两种形式，均解析为小数0.15，取值范围为[0%, 100%]。
+// 适用于采样率、比例等参数。
+func (fs *FlagSet) PercentVar(ptr *float64, short rune, long string, dft float64, desc string) {
+	p := addTyped(fs, ptr, short, long, dft, desc, parsePercent)
+	p.typ = "percent"
+}
+
+func parsePercent(s string) (float64, error) {
+	var (
+		f   float64
+		err error
+	)
+	if num, ok := strings.CutSuffix(s, "%"); ok {
+		f, err = strconv.ParseFloat(digits(num), 64)
+		f /= 100
+	} else {
+		f, err = strconv.ParseFloat(digits(s), 64)
+	}
+	if err != nil || math.IsNaN(f) {
+		return 0, fmt.Errorf("invalid percent: %q", s)
+	}
+	if f < 0 || f > 1 {
+		return 0, fmt.Errorf("percent %v out of range [0%%, 100%%]", s)
+	}
+	return f, nil
+}
@@ -0,0 +1,46 @@
+package flags
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPercent(t *testing.T) {
+	fs := New("percent", "")
+	rate := fs.Percent('r', "rate", 0.5, "sample rate")
+	fs.Handle(func(context.Context) {})
+
+	cases := []struct {
+		args []string
+		rate float64
+	}{
+		{nil, 0.5},
+		{[]string{"-r", "15%"}, 0.15},
+		{[]string{"--rate=0.15"}, 0.15},
+		{[]string{"-r", "100%"}, 1},
+		{[]string{"-r", "0"}, 0},
+		{[]string{"-r", "12.5%"}, 0.125},
+	}
+	for _, c := range cases {
+		fs.Reset()
+		_, err := fs.Run(context.Background(), c.args...)
+		if err != nil {
+			t.Fatalf("percent run %q: %v", c.args, err)
+		}
+		if *rate != c.rate {
+			t.Fatalf("percent run %q result: %v", c.args, *rate)
+		}
+	}
+
+	for _, val := range []string{"15", "101%", "-1%", "abc%", "%", "NaN"} {
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), "-r", val); err == nil {
+			t.Fatalf("percent run %v: no err", val)
+		}
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, "-r, --rate percent (default: 0.5)") {
+		t.Fatalf("percent usage: %v", usage)
+	}
+}
@@ -0,0 +1,126 @@
+package flags
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PlainUsage：帮助信息使用纯文本格式，每行均为"标签: 内容"形式，不使用缩进对齐，
+// 便于屏幕阅读器朗读，也便于通过管道交给其它工具处理。如：
+//
+//	Command: app
+//	Description: this is a test app desc
+//	Usage: app [option|command]
+//
+//	Option: -c, --config
+//	Value: string
+//	Default: "app.cfg"
+//	Description: config file
+//
+// 仅根命令有效。
+func (fs *FlagSet) PlainUsage() *FlagSet {
+	fs.unsealed()
+	fs.root().plainUsage = true
+	fs.touch()
+	return fs
+}
+
+func (fs *FlagSet) writePlainUsage(w io.Writer) {
+	name := fs.localFullName()
+	fmt.Fprintf(w, "Command: %v\n", name)
+	writePlainText(w, "Description", fs.localDesc())
+	if fs.deprecated != nil {
+		writePlainText(w, "Deprecated", plainHint(*fs.deprecated))
+	}
+	fmt.Fprintf(w, "Usage: %v", name)
+	fs.writeSynopsis(w)
+	fmt.Fprintf(w, "\n\n")
+
+	if fs.fn != nil {
+		for _, p := range fs.allParams() {
+			var names []string
+			if p.short != "" {
+				names = append(names, "-"+p.short)
+			}
+			if p.long != "" {
+				names = append(names, "--"+p.long)
+				if p.negatable {
+					names = append(names, "--no-"+p.long)
+				}
+			}
+			fmt.Fprintf(w, "Option: %v\n", strings.Join(names, ", "))
+			writePlainValue(w, p)
+			writePlainText(w, "Description", p.desc)
+			fmt.Fprintln(w)
+		}
+		for _, opt := range fs.builtinOptions() {
+			fmt.Fprintf(w, "Option: %v\n", opt.name)
+			writePlainText(w, "Description", opt.desc)
+			fmt.Fprintln(w)
+		}
+
+		for _, p := range fs.allArgs() {
+			fmt.Fprintf(w, "Argument: %v\n", p.arg)
+			writePlainValue(w, p)
+			if p == fs.rest && fs.restMin > 0 {
+				fmt.Fprintf(w, "Minimum: %v\n", fs.restMin)
+			}
+			writePlainText(w, "Description", p.desc)
+			fmt.Fprintln(w)
+		}
+	}
+
+	for _, cmd := range fs.cmds {
+		fmt.Fprintf(w, "Subcommand: %v\n", cmd.localName())
+		if l, ok := cmd.localized(); ok && len(l.aliases) > 0 {
+			fmt.Fprintf(w, "Aliases: %v\n", strings.Join(l.aliases, ", "))
+		}
+		writePlainText(w, "Description", cmd.localDesc())
+		if cmd.deprecated != nil {
+			writePlainText(w, "Deprecated", plainHint(*cmd.deprecated))
+		}
+		fmt.Fprintln(w)
+	}
+
+	if fs.root().pathPlugins {
+		for _, name := range fs.discoverPlugins() {
+			fmt.Fprintf(w, "Plugin: %v\n", name)
+		}
+	}
+}
+
+// writePlainValue：参数值类型及附加说明，每项一行。
+func writePlainValue(w io.Writer, p *param) {
+	typ := p.typ
+	if p.meta != "" {
+		typ = p.meta
+	}
+	if p.greedy {
+		typ += "..."
+	}
+	fmt.Fprintf(w, "Value: %v\n", typ)
+	if p.noOpt != nil {
+		fmt.Fprintf(w, "Value if omitted: %q\n", *p.noOpt)
+	}
+	for _, n := range usageNotes(p) {
+		label := strings.ToUpper(n.label[:1]) + n.label[1:]
+		writePlainText(w, label, plainHint(n.value))
+	}
+}
+
+// writePlainText：输出"label: text"，text为空时不输出，多行时后续各行原样输出。
+func writePlainText(w io.Writer, label, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(w, "%v: %v\n", label, text)
+}
+
+// plainHint：没有说明内容的标记（如未带提示的弃用）显示为yes。
+func plainHint(hint string) string {
+	if hint == "" {
+		return "yes"
+	}
+	return hint
+}
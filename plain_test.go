@@ -0,0 +1,43 @@
+package flags
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPlainUsage(t *testing.T) {
+	fs := New("app", "a test app").PlainUsage()
+	fs.Str('c', "config", "app.cfg", "config file")
+	fs.Bool(0, "color", true, "colorize output")
+	fs.Flag("color").Negatable().Env("APP_COLOR")
+	fs.Handle(func(context.Context) {})
+	Rest[string](fs, "files", 1, "input files")
+	fs.Cmd("old", "old command").Deprecate("")
+
+	want := `Command: app
+Description: a test app
+Usage: app [option|command] files...
+
+Option: -c, --config
+Value: string
+Default: "app.cfg"
+Description: config file
+
+Option: --color, --no-color
+Value: bool
+Default: true
+Env: APP_COLOR
+Description: colorize output
+
+Argument: files
+Value: []string
+Minimum: 1
+Description: input files
+
+Subcommand: old
+Description: old command
+Deprecated: yes`
+	if usage := fs.Usage(); usage != want {
+		t.Fatalf("plain usage:\n%v", usage)
+	}
+}
@@ -0,0 +1,32 @@
+package flags
+
+import "fmt"
+
+// PreprocessArgs：注册参数预处理函数，在解析前改写参数列表，如展开自定义别名、将旧版参数转换为新参数、按环境注入参数等。
+// 多次调用时按注册顺序依次执行，前一个函数的输出作为后一个函数的输入；任一函数返回错误时停止解析并返回该错误。
+// 在Run、Exec、Parse时执行，早于命令串联拆分。仅根命令有效。
+func (fs *FlagSet) PreprocessArgs(fn func(args []string) ([]string, error)) *FlagSet {
+	fs.unsealed()
+	if fn == nil {
+		panic(fmt.Errorf("flags: preprocess func: nil"))
+	}
+	root := fs.root()
+	root.preprocs = append(root.preprocs, fn)
+	return fs
+}
+
+// preprocess：依次执行参数预处理函数，返回的参数列表不与args共享底层数组。
+func (fs *FlagSet) preprocess(args []string) ([]string, error) {
+	preprocs := fs.root().preprocs
+	if len(preprocs) == 0 {
+		return args, nil
+	}
+	args = append([]string(nil), args...)
+	for _, fn := range preprocs {
+		var err error
+		if args, err = fn(args); err != nil {
+			return nil, fmt.Errorf("%v: preprocess args: %w", fs.fullName(), err)
+		}
+	}
+	return args, nil
+}
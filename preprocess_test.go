@@ -0,0 +1,63 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPreprocessArgs(t *testing.T) {
+	fs := New("app", "")
+	output := fs.Str('o', "output", "", "output file")
+	level := fs.Str(0, "level", "info", "log level")
+
+	// translate legacy option
+	fs.PreprocessArgs(func(args []string) ([]string, error) {
+		for i, arg := range args {
+			if arg == "-out" {
+				args[i] = "--output"
+			}
+		}
+		return args, nil
+	})
+	// inject option
+	fs.PreprocessArgs(func(args []string) ([]string, error) {
+		for _, arg := range args {
+			if arg == "--forbidden" {
+				return nil, errors.New("forbidden")
+			}
+		}
+		return append([]string{"--level", "debug"}, args...), nil
+	})
+
+	var path string
+	fs.Cmd("build", "").Handle(func(ctx context.Context) {
+		path = *output
+	})
+
+	args := []string{"-out", "a.txt", "build"}
+	_, err := fs.Run(context.Background(), args...)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if path != "a.txt" || *level != "debug" {
+		t.Fatalf("preprocessed output: %q, level: %q", path, *level)
+	}
+	if args[0] != "-out" {
+		t.Fatalf("preprocess modified caller args: %q", args)
+	}
+
+	m, err := fs.Parse("-out", "b.txt", "build")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if m.Path != "app build" || *output != "b.txt" {
+		t.Fatalf("parse preprocessed path: %q, output: %q", m.Path, *output)
+	}
+
+	_, err = fs.Run(context.Background(), "--forbidden", "build")
+	if err == nil || !strings.Contains(err.Error(), "preprocess args: forbidden") {
+		t.Fatalf("preprocess error: %v", err)
+	}
+}
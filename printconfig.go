@@ -0,0 +1,165 @@
+package flags
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Source：参数值来源
+type Source int
+
+const (
+	SourceDefault Source = iota // 默认值
+	SourceEnv                   // 环境变量，见Flag.Env
+	SourceFlag                  // 命令行参数
+	SourceConfig                // 配置文件，见FlagSet.ConfigFiles
+	SourceLast                  // 上次执行时的命令行参数，见FlagSet.RememberLast
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	case SourceConfig:
+		return "config"
+	case SourceLast:
+		return "last"
+	}
+	return fmt.Sprintf("Source(%d)", int(s))
+}
+
+// PrintConfig：开启内置参数--print-config。Run时如出现该参数，在合并默认值、环境变量、命令行参数后，
+// 将最终命令的所有参数值及其来源以TOML格式打印到stdout，不执行命令，用于排查参数值最终取自何处。
+// 通过Flag.Secret标记的参数值会被隐藏。开启后帮助信息中列出--print-config。
+func (fs *FlagSet) PrintConfig() *FlagSet {
+	fs.touch()
+	fs.root().printConfig = true
+	return fs
+}
+
+// WriteEffectiveConfig：将参数当前值及其来源以TOML格式写入w，每个参数一行，来源以注释形式给出，如：
+//
+//	output = "app.log" # env
+func (fs *FlagSet) WriteEffectiveConfig(w io.Writer) error {
+	b := new(bytes.Buffer)
+	fmt.Fprintf(b, "# %v - %v\n", fs.fullName(), fs.desc)
+	for _, p := range fs.allParams() {
+		val, err := tomlValue(configValue(p, reflect.ValueOf(p.ptr).Elem()))
+		if err != nil {
+			return fmt.Errorf("%v: option %v: %w", fs.fullName(), p.name(), err)
+		}
+		if p.secret {
+			val = jsonString("******")
+		}
+		fmt.Fprintf(b, "%v = %v # %v\n", tomlKey(p.configKey()), val, p.source)
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// ChangedFlag：值与默认值不同的参数，见FlagSet.Changed。
+type ChangedFlag struct {
+	Name    string // 参数名称，如"--output"或"-o"
+	Value   any    // 参数最终值
+	Default any    // 参数默认值
+	Source  Source // 参数值来源
+}
+
+// Changed：列出最终值与默认值不同的参数及其来源，按注册顺序排列，需在解析完成后调用。
+// 与默认值相同的参数即使在命令行中显式给出也不会列出。
+func (fs *FlagSet) Changed() []ChangedFlag {
+	var list []ChangedFlag
+	for _, p := range fs.changed() {
+		list = append(list, ChangedFlag{
+			Name:    p.name(),
+			Value:   reflect.ValueOf(p.ptr).Elem().Interface(),
+			Default: p.effectiveDft().Interface(),
+			Source:  p.source,
+		})
+	}
+	return list
+}
+
+func (fs *FlagSet) changed() []*param {
+	var list []*param
+	for _, p := range fs.allParams() {
+		if p.source == SourceDefault {
+			continue
+		}
+		val := configValue(p, reflect.ValueOf(p.ptr).Elem())
+		if jsonValue(val) != jsonValue(configValue(p, p.effectiveDft())) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// effectiveDft：参数未设置时将取得的默认值，延迟计算的默认值会在此时计算。
+func (p *param) effectiveDft() reflect.Value {
+	if p.dftFn.IsValid() {
+		return p.dftFn.Call(nil)[0]
+	}
+	return p.dftValue()
+}
+
+// PrintChanged：开启内置参数--print-changed。Run时如出现该参数，解析完成后将最终命令中值与默认值不同的参数
+// 以TOML格式打印到stdout，不执行命令，用于排查部署时的错误配置。通过Flag.Secret标记的参数值会被隐藏。
+// 开启后帮助信息中列出--print-changed。
+func (fs *FlagSet) PrintChanged() *FlagSet {
+	fs.touch()
+	fs.root().printChanged = true
+	return fs
+}
+
+// WriteChanged：将值与默认值不同的参数以TOML格式写入w，来源及默认值以注释形式给出，如：
+//
+//	output = "app.log" # env, default: "out.txt"
+func (fs *FlagSet) WriteChanged(w io.Writer) error {
+	b := new(bytes.Buffer)
+	fmt.Fprintf(b, "# %v - %v\n", fs.fullName(), fs.desc)
+	for _, p := range fs.changed() {
+		val, err := tomlValue(configValue(p, reflect.ValueOf(p.ptr).Elem()))
+		if err != nil {
+			return fmt.Errorf("%v: option %v: %w", fs.fullName(), p.name(), err)
+		}
+		dft, err := tomlValue(configValue(p, p.effectiveDft()))
+		if err != nil {
+			return fmt.Errorf("%v: option %v: %w", fs.fullName(), p.name(), err)
+		}
+		if p.secret {
+			val, dft = jsonString("******"), jsonString("******")
+		}
+		fmt.Fprintf(b, "%v = %v # %v, default: %v\n", tomlKey(p.configKey()), val, p.source, dft)
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// DryRun：只解析不执行。Run完成参数解析、默认值及环境变量合并、位置参数校验后，不执行命令，
+// 而是将匹配到的命令、参数值及其来源以WriteEffectiveConfig的格式写入w，位置参数及外部插件以注释形式给出。
+// 适用于在CI中校验生成的命令行。w为nil时关闭。
+func (fs *FlagSet) DryRun(w io.Writer) *FlagSet {
+	fs.unsealed()
+	fs.root().dryRun = w
+	return fs
+}
+
+func (fs *FlagSet) writeDryRun(w io.Writer, positional []string) error {
+	if err := fs.WriteEffectiveConfig(w); err != nil {
+		return err
+	}
+	var err error
+	if len(positional) > 0 {
+		_, err = fmt.Fprintf(w, "# args: %v\n", jsonValue(positional))
+	}
+	if err == nil && fs.plugin != "" {
+		_, err = fmt.Fprintf(w, "# plugin: %v %v\n", fs.plugin, jsonValue(fs.pluginArgs))
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrintConfig(t *testing.T) {
+	t.Setenv("FLAGS_TEST_TOKEN", "s3cr3t")
+
+	fs := New("app", "print config").PrintConfig()
+	fs.Str('o', "output", "out.txt", "output file")
+	fs.Str(NoShort, "token", "", "api token")
+	fs.Flag("token").Env("FLAGS_TEST_TOKEN").Secret()
+	n := fs.Int('n', NoLong, 3, "count")
+
+	var ran bool
+	fs.Handle(func(context.Context) { ran = true })
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	_, err = fs.Run(context.Background(), "-n", "5", "--print-config")
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if ran {
+		t.Fatalf("handler should not run with --print-config")
+	}
+	if *n != 5 {
+		t.Fatalf("-n: %v", *n)
+	}
+
+	out, _ := io.ReadAll(r)
+	exp := `# app - print config
+output = "out.txt" # default
+token = "******" # env
+n = 5 # flag
+`
+	if string(out) != exp {
+		t.Fatalf("print config:\n%s\nexpected:\n%s", out, exp)
+	}
+	if usage := fs.Usage(); !strings.Contains(usage, "  --print-config\n") {
+		t.Fatalf("usage: %v", usage)
+	}
+}
+
+func TestPrintConfigDisabled(t *testing.T) {
+	fs := New("app", "print config")
+	fs.Handle(func(context.Context) {})
+	_, err := fs.Run(context.Background(), "--print-config")
+	if err == nil || !strings.Contains(err.Error(), "unknown option") {
+		t.Fatalf("--print-config without PrintConfig: %v", err)
+	}
+}
+
+func TestWriteEffectiveConfigSubcmd(t *testing.T) {
+	fs := New("app", "print config").PrintConfig()
+	fs.Str(NoShort, "output", "out.txt", "output file")
+	sub := fs.Cmd("sub", "sub command")
+	Slice[string](sub, NoShort, "tags", nil, "tags")
+	sub.Handle(func(context.Context) {})
+
+	a := newArgs("--output", "x.txt", "sub", "--tags", "a", "--tags", "b", "--print-config")
+	f, err := fs._parse(a)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !a.printConfig {
+		t.Fatalf("--print-config in sub command not recognized")
+	}
+
+	b := new(bytes.Buffer)
+	if err := f.WriteEffectiveConfig(b); err != nil {
+		t.Fatalf("write effective config: %v", err)
+	}
+	exp := `# app sub - sub command
+output = "x.txt" # flag
+tags = ["a", "b"] # flag
+`
+	if b.String() != exp {
+		t.Fatalf("effective config:\n%s\nexpected:\n%s", b, exp)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	t.Setenv("FLAGS_TEST_TOKEN", "s3cr3t")
+
+	fs := New("app", "changed").PrintChanged()
+	fs.Str('o', "output", "out.txt", "output file")
+	fs.Str(NoShort, "token", "", "api token")
+	fs.Flag("token").Env("FLAGS_TEST_TOKEN").Secret()
+	fs.Int('n', NoLong, 3, "count")
+	Slice[string](fs, NoShort, "tags", nil, "tags")
+
+	a := newArgs("-o", "out.txt", "-n", "5", "--tags", "a", "--print-changed")
+	f, err := fs._parse(a)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !a.printChanged {
+		t.Fatalf("--print-changed not recognized")
+	}
+
+	changed := f.Changed()
+	if len(changed) != 3 {
+		t.Fatalf("changed: %+v", changed)
+	}
+	if c := changed[0]; c.Name != "--token" || c.Value != "s3cr3t" || c.Default != "" || c.Source != SourceEnv {
+		t.Fatalf("changed token: %+v", c)
+	}
+	if c := changed[1]; c.Name != "-n" || c.Value != 5 || c.Default != 3 || c.Source != SourceFlag {
+		t.Fatalf("changed -n: %+v", c)
+	}
+
+	b := new(bytes.Buffer)
+	if err := f.WriteChanged(b); err != nil {
+		t.Fatalf("write changed: %v", err)
+	}
+	exp := `# app - changed
+token = "******" # env, default: "******"
+n = 5 # flag, default: 3
+tags = ["a"] # flag, default: []
+`
+	if b.String() != exp {
+		t.Fatalf("changed:\n%s\nexpected:\n%s", b, exp)
+	}
+	fs.Handle(func(context.Context) {})
+	if usage := fs.Usage(); !strings.Contains(usage, "  --print-changed\n") {
+		t.Fatalf("usage: %v", usage)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	b := new(bytes.Buffer)
+	fs := New("app", "dry run").DryRun(b)
+	fs.Str('o', "output", "out.txt", "output file")
+	cp := fs.Cmd("cp", "copy")
+	Arg[string](cp, "src", "", "")
+	Arg[string](cp, "dst", "", "")
+	cp.ValidateArgs(ExactArgs(2))
+	var ran bool
+	cp.Handle(func(context.Context) { ran = true })
+
+	if _, err := fs.Run(context.Background(), "cp", "a", "b", "-o", "x.txt"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if ran {
+		t.Fatalf("handler should not run in dry run mode")
+	}
+	exp := `# app cp - copy
+output = "x.txt" # flag
+# args: ["a","b"]
+`
+	if b.String() != exp {
+		t.Fatalf("dry run:\n%s\nexpected:\n%s", b, exp)
+	}
+
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "cp", "a"); err == nil {
+		t.Fatalf("dry run should validate arguments")
+	}
+	if _, err := fs.Run(context.Background()); !errors.Is(err, ErrNoExecFunc) {
+		t.Fatalf("dry run without handler: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// UseProfiling：注册"--cpuprofile"、"--memprofile"、"--trace"参数，以及在Handler前后开启、停止
+// pprof和runtime/trace的中间件，参数值为输出文件路径，为空时不开启。
+// 内存profile在Handler执行完成后写入。应在注册子命令及Handler之前调用，以便子命令继承参数及中间件。
+func UseProfiling(fs *FlagSet) *FlagSet {
+	cpu := fs.Str(NoShort, "cpuprofile", "", "write cpu profile to file")
+	mem := fs.Str(NoShort, "memprofile", "", "write memory profile to file")
+	tr := fs.Str(NoShort, "trace", "", "write execution trace to file")
+
+	return fs.Use(func(ctx context.Context, next Handler) {
+		if *cpu != "" {
+			if f := createProfile(*cpu, fs.Stderr()); f != nil {
+				defer f.Close()
+				if err := pprof.StartCPUProfile(f); err != nil {
+					fmt.Fprintf(fs.Stderr(), "flags: start cpu profile: %v\n", err)
+				} else {
+					defer pprof.StopCPUProfile()
+				}
+			}
+		}
+		if *tr != "" {
+			if f := createProfile(*tr, fs.Stderr()); f != nil {
+				defer f.Close()
+				if err := trace.Start(f); err != nil {
+					fmt.Fprintf(fs.Stderr(), "flags: start trace: %v\n", err)
+				} else {
+					defer trace.Stop()
+				}
+			}
+		}
+		if *mem != "" {
+			defer writeHeapProfile(*mem, fs.Stderr())
+		}
+		next(ctx)
+	})
+}
+
+func createProfile(name string, stderr io.Writer) *os.File {
+	f, err := os.Create(name)
+	if err != nil {
+		fmt.Fprintf(stderr, "flags: create profile: %v\n", err)
+		return nil
+	}
+	return f
+}
+
+func writeHeapProfile(name string, stderr io.Writer) {
+	f := createProfile(name, stderr)
+	if f == nil {
+		return
+	}
+	defer f.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		fmt.Fprintf(stderr, "flags: write memory profile: %v\n", err)
+	}
+}
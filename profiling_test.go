@@ -0,0 +1,28 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUseProfiling(t *testing.T) {
+	dir := t.TempDir()
+	fs := UseProfiling(New("prof", "profiling"))
+	var ran bool
+	fs.Handle(func(context.Context) { ran = true })
+
+	cpu := filepath.Join(dir, "cpu.pprof")
+	mem := filepath.Join(dir, "mem.pprof")
+	tr := filepath.Join(dir, "trace.out")
+	_, err := fs.Run(context.Background(), "--cpuprofile", cpu, "--memprofile", mem, "--trace", tr)
+	if err != nil || !ran {
+		t.Fatalf("run: %v, ran: %v", err, ran)
+	}
+	for _, name := range []string{cpu, mem, tr} {
+		if fi, err := os.Stat(name); err != nil || fi.Size() == 0 {
+			t.Fatalf("profile %v: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package flags
+
+import (
+	"context"
+	"time"
+)
+
+// UseRetry：注册"--retries"、"--retry-interval"参数，以及Handler出错时按指数退避重试的中间件。
+// 第n次重试前等待retry-interval*2^(n-1)，ctx结束时停止重试。仅对HandleE返回的错误重试。
+// 应在注册子命令及Handler之前调用，以便子命令继承参数及中间件。
+func UseRetry(fs *FlagSet) *FlagSet {
+	retries := fs.Int(NoShort, "retries", 0, "max retries when the command fails")
+	interval := fs.Duration(NoShort, "retry-interval", time.Second, "initial retry interval, doubled on each retry")
+
+	return fs.Use(func(ctx context.Context, next Handler) {
+		inv := getRun(ctx)
+		next(ctx)
+		if inv == nil {
+			return
+		}
+
+		wait := *interval
+		for i := 0; i < *retries && inv.err != nil; i++ {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			wait *= 2
+
+			inv.err = nil
+			next(ctx)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUseRetry(t *testing.T) {
+	fs := UseRetry(New("retry", ""))
+	var calls int
+	fs.HandleE(func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+
+	_, err := fs.Run(context.Background(), "--retries", "5", "--retry-interval", "1ms")
+	if err != nil || calls != 3 {
+		t.Fatalf("retry run: %v, calls: %v", err, calls)
+	}
+
+	fs.Reset()
+	calls = 0
+	_, err = fs.Run(context.Background(), "--retries", "1", "--retry-interval", "1ms")
+	if err == nil || calls != 2 {
+		t.Fatalf("retry exhausted: %v, calls: %v", err, calls)
+	}
+
+	fs.Reset()
+	calls = 0
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = fs.Run(ctx, "--retries", "5", "--retry-interval", "1h")
+	if err == nil || calls != 1 {
+		t.Fatalf("retry canceled: %v, calls: %v", err, calls)
+	}
+}
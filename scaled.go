@@ -0,0 +1,131 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Scaled：整数参数值可带数量级后缀，适用于数量及大小等参数。对整数slice、map value同样有效。
+// 十进制后缀k(K)、M、G、T、P、E为1000的幂，二进制后缀Ki、Mi、Gi、Ti、Pi、Ei为1024的幂，
+// 如"--limit 10k"即10000，"--memory 2Gi"即2147483648，"1.5k"即1500，结果须为整数。
+func (f *Flag) Scaled() *Flag {
+	f.fs.unsealed()
+	leaf := f.p
+	for leaf.elem != nil {
+		leaf = leaf.elem
+	}
+	switch leaf.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		panic(fmt.Errorf("flags: option %v: scaled requires integer type, got %v", f.p.name(), f.p.typ))
+	}
+	if leaf.rtyp == typDuration || leaf.rtyp == typFileMode || leaf.rtyp == typLogLevel {
+		panic(fmt.Errorf("flags: option %v: scaled requires integer type, got %v", f.p.name(), f.p.typ))
+	}
+
+	for p := f.p; p != nil; p = p.elem {
+		p.scaled = true
+	}
+	f.p.set = nil // 经由_parseInts/_parseUints解析
+	f.fs.touch()
+	return f
+}
+
+var scaleSuffixes = []struct {
+	suffix string
+	mult   uint64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50}, {"Ei", 1 << 60},
+	{"k", 1e3}, {"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+}
+
+// splitScale：拆分数量级后缀，无后缀时mult为1。
+func splitScale(s string) (num string, mult uint64) {
+	for _, x := range scaleSuffixes {
+		if len(s) > len(x.suffix) && strings.HasSuffix(s, x.suffix) {
+			return s[:len(s)-len(x.suffix)], x.mult
+		}
+	}
+	return s, 1
+}
+
+// scaledRange：超出int64、uint64范围的错误，与strconv相同地包装strconv.ErrRange，以便OverflowClamp取最大/最小值。
+func scaledRange(s string) error {
+	return fmt.Errorf("scaled number %q: %w", s, strconv.ErrRange)
+}
+
+// parseScaledInt：解析带数量级后缀的有符号整数。超出范围时同strconv.ParseInt，返回最大/最小值及ErrRange。
+func parseScaledInt(s string) (int64, error) {
+	num, mult := splitScale(s)
+	if mult == 1 {
+		return strconv.ParseInt(num, 10, 64)
+	}
+	if strings.Contains(num, ".") {
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf("invalid scaled number: %q", s)
+		}
+		v := f * float64(mult)
+		switch {
+		case v >= math.MaxInt64:
+			return math.MaxInt64, scaledRange(s)
+		case v < math.MinInt64:
+			return math.MinInt64, scaledRange(s)
+		case v != math.Trunc(v):
+			return 0, fmt.Errorf("invalid scaled number: %q", s)
+		}
+		return int64(v), nil
+	}
+	i, err := strconv.ParseInt(num, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return i, scaledRange(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid scaled number: %q", s)
+	}
+	if i > math.MaxInt64/int64(mult) {
+		return math.MaxInt64, scaledRange(s)
+	}
+	if i < math.MinInt64/int64(mult) {
+		return math.MinInt64, scaledRange(s)
+	}
+	return i * int64(mult), nil
+}
+
+// parseScaledUint：解析带数量级后缀的无符号整数。超出范围时同strconv.ParseUint，返回最大值及ErrRange。
+func parseScaledUint(s string) (uint64, error) {
+	num, mult := splitScale(s)
+	if mult == 1 {
+		return strconv.ParseUint(num, 10, 64)
+	}
+	if strings.Contains(num, ".") {
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) || f < 0 {
+			return 0, fmt.Errorf("invalid scaled number: %q", s)
+		}
+		v := f * float64(mult)
+		switch {
+		case v >= math.MaxUint64:
+			return math.MaxUint64, scaledRange(s)
+		case v != math.Trunc(v):
+			return 0, fmt.Errorf("invalid scaled number: %q", s)
+		}
+		return uint64(v), nil
+	}
+	i, err := strconv.ParseUint(num, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return i, scaledRange(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid scaled number: %q", s)
+	}
+	if i > math.MaxUint64/mult {
+		return math.MaxUint64, scaledRange(s)
+	}
+	return i * mult, nil
+}
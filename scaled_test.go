@@ -0,0 +1,88 @@
+package flags
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestScaled(t *testing.T) {
+	fs := New("scaled", "")
+	limit := fs.Int('l', "limit", 0, "limit")
+	memory := fs.Uint64('m', "memory", 0, "memory")
+	small := fs.Int8(NoShort, "small", 0, "small")
+	sizes := Slice[int32](fs, 's', "sizes", nil, "sizes")
+	plain := fs.Int('p', "plain", 0, "plain")
+	fs.Flag("limit").Scaled()
+	fs.Flag("memory").Scaled()
+	fs.Flag("small").Scaled()
+	fs.Flag("sizes").Scaled()
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "-l", "10k", "-m", "2Gi", "-s", "1.5k", "-s", "-2M", "-s", "7", "-p", "3")
+	if err != nil {
+		t.Fatalf("scaled run: %v", err)
+	}
+	if *limit != 10000 || *memory != 2<<30 || !sliceEqual(*sizes, 1500, -2000000, 7) || *plain != 3 {
+		t.Fatalf("scaled result: %v %v %v %v", *limit, *memory, *sizes, *plain)
+	}
+
+	for _, args := range [][]string{
+		{"-p", "1k"},
+		{"-l", "1.0001k"},
+		{"-l", "10E"},
+		{"-m", "-1k"},
+		{"--small", "1k"},
+		{"-l", "k"},
+	} {
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), args...); err == nil {
+			t.Fatalf("scaled run %q: no err, %v %v %v", args, *limit, *memory, *small)
+		}
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, "--limit int (scaled)") {
+		t.Fatalf("scaled usage: %v", usage)
+	}
+}
+
+func TestScaledClamp(t *testing.T) {
+	fs := New("scaled", "").SetOverflow(OverflowClamp)
+	small := fs.Int8(NoShort, "small", 0, "small")
+	limit := fs.Int64('l', "limit", 0, "limit")
+	memory := fs.Uint64('m', "memory", 0, "memory")
+	sizes := Slice[int32](fs, 's', "sizes", nil, "sizes")
+	for _, name := range []string{"small", "limit", "memory", "sizes"} {
+		fs.Flag(name).Scaled()
+	}
+	fs.Handle(func(context.Context) {})
+
+	// values out of the range of the type or of 64 bits are clamped like plain integers
+	for _, c := range []struct {
+		args  []string
+		check func() bool
+	}{
+		{[]string{"--small", "1k"}, func() bool { return *small == 127 }},
+		{[]string{"--small", "-1k"}, func() bool { return *small == -128 }},
+		{[]string{"-l", "10E"}, func() bool { return *limit == math.MaxInt64 }},
+		{[]string{"-l", "-10.5E"}, func() bool { return *limit == math.MinInt64 }},
+		{[]string{"-l", "99999999999999999999k"}, func() bool { return *limit == math.MaxInt64 }},
+		{[]string{"-m", "20Ei"}, func() bool { return *memory == math.MaxUint64 }},
+		{[]string{"-s", "5G", "-s", "-5G"}, func() bool { return sliceEqual(*sizes, math.MaxInt32, math.MinInt32) }},
+	} {
+		fs.Reset()
+		if _, err := fs.Run(context.Background(), c.args...); err != nil || !c.check() {
+			t.Fatalf("scaled clamp %q: %v, %v %v %v %v", c.args, err, *small, *limit, *memory, *sizes)
+		}
+		if len(fs.Warnings()) == 0 {
+			t.Fatalf("scaled clamp %q: no warning", c.args)
+		}
+	}
+
+	// fractions are still invalid
+	fs.Reset()
+	if _, err := fs.Run(context.Background(), "-l", "1.0001k"); err == nil {
+		t.Fatalf("scaled clamp fraction: no err")
+	}
+}
@@ -0,0 +1,106 @@
+package flags
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// CommandSchema：命令的机器可读描述，见FlagSet.Schema。
+type CommandSchema struct {
+	Name     string              `json:"name"`
+	Desc     string              `json:"desc,omitempty"`
+	Runnable bool                `json:"runnable"` // 是否有Handler
+	Flags    []FlagSchema        `json:"flags,omitempty"`
+	Args     []ArgSchema         `json:"args,omitempty"`
+	Aliases  map[string][]string `json:"aliases,omitempty"`
+	Commands []CommandSchema     `json:"commands,omitempty"`
+}
+
+// FlagSchema：参数的机器可读描述。
+type FlagSchema struct {
+	Short     string `json:"short,omitempty"`
+	Long      string `json:"long,omitempty"`
+	Type      string `json:"type"`
+	Desc      string `json:"desc,omitempty"`
+	Default   any    `json:"default,omitempty"`
+	Metavar   string `json:"metavar,omitempty"`
+	Env       string `json:"env,omitempty"`
+	NoOpt     string `json:"noOpt,omitempty"`
+	Negatable bool   `json:"negatable,omitempty"`
+	Greedy    bool   `json:"greedy,omitempty"`
+	Secret    bool   `json:"secret,omitempty"`
+	Mode      string `json:"mode,omitempty"` // slice参数的赋值方式
+}
+
+// ArgSchema：位置参数的机器可读描述。
+type ArgSchema struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Desc     string `json:"desc,omitempty"`
+	Default  any    `json:"default,omitempty"`
+	Variadic bool   `json:"variadic,omitempty"` // 是否为可变位置参数，见FlagSet.RestVar
+	Min      int    `json:"min,omitempty"`      // 可变位置参数最少个数
+}
+
+// Schema：将整个命令树（命令、参数、类型、默认值、约束等）序列化为JSON，
+// 供GUI生成、文档生成、其它shell的补全等外部工具使用。
+// 延迟计算的默认值及Secret参数的默认值不会输出。
+func (fs *FlagSet) Schema() ([]byte, error) {
+	return json.MarshalIndent(fs.schema(), "", "  ")
+}
+
+func (fs *FlagSet) schema() CommandSchema {
+	s := CommandSchema{
+		Name:     fs.name,
+		Desc:     fs.desc,
+		Runnable: fs.fn != nil,
+	}
+	for _, p := range fs.allParams() {
+		f := FlagSchema{
+			Short:     p.short,
+			Long:      p.long,
+			Type:      p.typ,
+			Desc:      p.desc,
+			Metavar:   p.meta,
+			Env:       p.env,
+			Negatable: p.negatable,
+			Greedy:    p.greedy,
+			Secret:    p.secret,
+			Default:   p.schemaDft(),
+		}
+		if p.noOpt != nil {
+			f.NoOpt = *p.noOpt
+		}
+		if p.isSlice() {
+			f.Mode = p.mode.String()
+		}
+		s.Flags = append(s.Flags, f)
+	}
+	for _, p := range fs.allArgs() {
+		a := ArgSchema{
+			Name:    p.arg,
+			Type:    p.typ,
+			Desc:    p.desc,
+			Default: p.schemaDft(),
+		}
+		if p == fs.rest {
+			a.Variadic = true
+			a.Min = fs.restMin
+		}
+		s.Args = append(s.Args, a)
+	}
+	if len(fs.aliases) > 0 {
+		s.Aliases = fs.aliases
+	}
+	for _, cmd := range fs.cmds {
+		s.Commands = append(s.Commands, cmd.schema())
+	}
+	return s
+}
+
+func (p *param) schemaDft() any {
+	if p.dft == nil || p.secret {
+		return nil
+	}
+	return configValue(p, reflect.ValueOf(p.dft))
+}
@@ -0,0 +1,54 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSchema(t *testing.T) {
+	fs := New("app", "schema")
+	fs.Str('o', "output", "out.txt", "output file")
+	fs.Str(NoShort, "token", "secret", "")
+	fs.Flag("token").Env("APP_TOKEN").Secret()
+	fs.Alias("ls", "list")
+	list := fs.Cmd("list", "list items")
+	Slice[string](list, 't', "tags", nil, "")
+	Arg[time.Duration](list, "timeout", time.Second, "")
+	Rest[string](list, "ITEM", 1, "items")
+	list.Handle(func(context.Context) {})
+
+	b, err := fs.Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	var s CommandSchema
+	if err = json.Unmarshal(b, &s); err != nil {
+		t.Fatalf("unmarshal schema %s: %v", b, err)
+	}
+
+	if s.Name != "app" || s.Runnable || len(s.Flags) != 2 || len(s.Commands) != 1 || len(s.Aliases["ls"]) != 1 {
+		t.Fatalf("schema: %s", b)
+	}
+	if f := s.Flags[0]; f.Short != "o" || f.Long != "output" || f.Type != "string" || f.Default != "out.txt" {
+		t.Fatalf("output schema: %+v", f)
+	}
+	if f := s.Flags[1]; f.Default != nil || !f.Secret || f.Env != "APP_TOKEN" {
+		t.Fatalf("token schema: %+v", f)
+	}
+
+	c := s.Commands[0]
+	if c.Name != "list" || !c.Runnable || len(c.Flags) != 3 || len(c.Args) != 2 {
+		t.Fatalf("list schema: %+v", c)
+	}
+	if f := c.Flags[2]; f.Type != "[]string" || f.Mode != "append" {
+		t.Fatalf("tags schema: %+v", f)
+	}
+	if a := c.Args[0]; a.Type != "duration" || a.Default != "1s" {
+		t.Fatalf("timeout schema: %+v", a)
+	}
+	if a := c.Args[1]; !a.Variadic || a.Min != 1 || a.Type != "[]string" {
+		t.Fatalf("items schema: %+v", a)
+	}
+}
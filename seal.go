@@ -0,0 +1,163 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Seal：结束注册，之后命令树不可修改，再注册参数、子命令、Handler，修改参数属性或ConfigFiles、SetStdio等根命令选项时panic。
+// Seal时预先生成各命令的帮助信息，之后Usage、Command等只读取命令树，可在多个goroutine中并发调用。
+// 参数值绑定在注册时的变量上，同一命令树仍不能并发Run；并发执行时每次先调用Clone得到独立的副本。仅根命令有效。
+func (fs *FlagSet) Seal() *FlagSet {
+	root := fs.root()
+	if root.sealed {
+		return fs
+	}
+	root.walk(func(f *FlagSet) { f.Usage() })
+	root.sealed = true
+	return fs
+}
+
+// Sealed：是否已调用Seal。
+func (fs *FlagSet) Sealed() bool {
+	return fs.root().sealed
+}
+
+// unsealed：检查命令树未Seal，否则panic。
+func (fs *FlagSet) unsealed() {
+	if fs.root().sealed {
+		panic(fmt.Errorf("flags: command %v is sealed", fs.fullName()))
+	}
+}
+
+// walk：遍历命令树中的所有命令及语句（见Stmt），包括fs本身。
+// 在语句上注册的子命令保存在所属命令的cmds中，因此每个命令只遍历一次。
+func (fs *FlagSet) walk(fn func(f *FlagSet)) {
+	fn(fs)
+	for _, s := range fs.stmts {
+		s.walk(fn)
+	}
+	for _, cmd := range fs.cmds {
+		cmd.walk(fn)
+	}
+}
+
+// Clone：复制已Seal的命令树，返回副本中与fs对应的命令。副本中参数值保存在新的变量中，
+// 与原命令树及其它副本互不影响，因此不同副本可以并发Run，如服务端每个请求使用一个副本。
+// 注册时绑定的变量不随副本解析而变化，Handler、中间件及DefaultFrom的函数应通过当前命令读取参数值，如：
+//
+//	fs.Handle(func(ctx context.Context) {
+//		n := flags.Value[int](flags.CommandFromContext(ctx), "num")
+//	})
+//
+// 命令树未Seal，或包含OutputFile、Reader、Writer等打开资源的参数时panic。Clone本身可并发调用。
+func (fs *FlagSet) Clone() *FlagSet {
+	if !fs.Sealed() {
+		panic(fmt.Errorf("flags: clone command %v: not sealed", fs.fullName()))
+	}
+	c := &cloner{
+		cmds:   make(map[*FlagSet]*FlagSet),
+		params: make(map[*param]*param),
+	}
+	return c.cmd(fs)
+}
+
+// cloner：复制命令树，记录原命令、参数到副本的映射，保证共享的命令、参数只复制一次。
+type cloner struct {
+	cmds   map[*FlagSet]*FlagSet
+	params map[*param]*param
+}
+
+func (c *cloner) cmd(fs *FlagSet) *FlagSet {
+	if fs == nil {
+		return nil
+	}
+	if n := c.cmds[fs]; n != nil {
+		return n
+	}
+	n := new(FlagSet)
+	*n = *fs
+	c.cmds[fs] = n
+
+	n.origin = fs
+	n.idx = nil
+	n.localIdx = nil
+	n.localFor = ""
+	n.warnings = nil
+	n.parent = c.cmd(fs.parent)
+	n.stmt = c.cmd(fs.stmt)
+	n.stmts = c.cmdList(fs.stmts)
+	n.heirs = c.cmdList(fs.heirs)
+	n.cmds = c.cmdList(fs.cmds)
+	if fs.cmdIdx != nil {
+		n.cmdIdx = make(map[string]*FlagSet, len(fs.cmdIdx))
+		for name, cmd := range fs.cmdIdx {
+			n.cmdIdx[name] = c.cmd(cmd)
+		}
+	}
+	n.params = c.paramList(fs.params)
+	n.posArgs = c.paramList(fs.posArgs)
+	n.rest = c.param(fs.rest)
+	return n
+}
+
+func (c *cloner) cmdList(cmds []*FlagSet) []*FlagSet {
+	if cmds == nil {
+		return nil
+	}
+	list := make([]*FlagSet, len(cmds))
+	for i, cmd := range cmds {
+		list[i] = c.cmd(cmd)
+	}
+	return list
+}
+
+// param：复制参数，副本的值保存在新的零值变量中，解析时按默认值规则重新赋值。
+// elem、key解析时会暂存元素的值，同样需要复制。
+func (c *cloner) param(p *param) *param {
+	if p == nil {
+		return nil
+	}
+	if n := c.params[p]; n != nil {
+		return n
+	}
+	if p.res != nil {
+		panic(fmt.Errorf("flags: clone option %v: resource options cannot be cloned", p.name()))
+	}
+	n := new(param)
+	*n = *p
+	c.params[p] = n
+
+	n.ptr = reflect.New(p.rtyp).Interface()
+	n.parsed = false
+	n.source = SourceDefault
+	n.owner = c.cmd(p.owner)
+	n.deps = c.paramList(p.deps)
+	n.elem = c.param(p.elem)
+	n.key = c.param(p.key)
+	return n
+}
+
+func (c *cloner) paramList(params []*param) []*param {
+	if params == nil {
+		return nil
+	}
+	list := make([]*param, len(params))
+	for i, p := range params {
+		list[i] = c.param(p)
+	}
+	return list
+}
+
+// counterpart：当前执行的命令树中与fs对应的命令，即在Clone的副本中执行时，返回fs的副本。
+func (fs *FlagSet) counterpart(ctx context.Context) *FlagSet {
+	if inv := getRun(ctx); inv != nil {
+		for f := inv.cmd; f != nil; f = f.parent {
+			if f == fs || f.origin == fs {
+				return f
+			}
+		}
+	}
+	return fs
+}
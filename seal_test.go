@@ -0,0 +1,169 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSeal(t *testing.T) {
+	fs := New("app", "")
+	fs.Int('n', "num", 1, "")
+	Slice[string](fs, 't', "tags", nil, "")
+	Map[string, int](fs, 'm', "map", nil, "")
+	fs.Bool('v', "verbose", false, "")
+	fs.Duration(0, "timeout", 0, "")
+	fs.Dir(0, "dir", "", "")
+	build := fs.Cmd("build", "")
+	build.Handle(func(context.Context) {})
+	stmt := fs.Stmt()
+	stmt.Cmd("deploy", "").Handle(func(context.Context) {})
+	fs.Seal()
+
+	if !build.Sealed() || fs.Usage() == "" {
+		t.Fatalf("sealed: %v", build.Sealed())
+	}
+	// usage of statements is built by Seal too
+	if stmt.usage == "" {
+		t.Fatalf("statement usage not built by seal")
+	}
+
+	registers := []func(){
+		func() { fs.Str('o', "output", "", "") },
+		func() { fs.Cmd("test", "") },
+		func() { build.Handle(func(context.Context) {}) },
+		func() { build.Use(func(ctx context.Context, next Handler) { next(ctx) }) },
+		func() { fs.Flag("num").Env("APP_NUM") },
+		func() { fs.Stmt() },
+		func() { fs.Alias("b", "build") },
+		func() { ArgVar(build, new(string), "target", "", "") },
+		func() { fs.SetLimits(Limits{MaxArgs: 10}) },
+		func() { fs.OnWarning(func(Warning) {}) },
+		func() { build.OnUnknownCommand(nil) },
+		func() { fs.ChainCommands("+") },
+		func() { fs.ConfigFiles("app.json") },
+		func() { fs.XDGConfig("") },
+		func() { fs.RememberLast("") },
+		func() { fs.SetStdio(nil, nil, nil) },
+		func() { fs.PreprocessArgs(func(args []string) ([]string, error) { return args, nil }) },
+		func() { fs.SetLookupEnv(nil) },
+		func() { fs.SetClock(nil) },
+		func() { fs.StrictConfig() },
+		func() { fs.SetExiter(nil) },
+		func() { fs.ExitCode(context.Canceled, 2) },
+		func() { ExitCodeAs[*strconv.NumError](fs, 2) },
+		func() { build.OnUnknownFlag(nil) },
+		func() { build.ValidateArgs(NoArgs) },
+		func() { fs.OnFlagParsed(nil) },
+		func() { fs.OnCommandResolved(nil) },
+		func() { fs.OnDefaultsApplied(nil) },
+		func() { fs.SingleDashLong() },
+		func() { fs.SetNormalizeFunc(strings.ToLower) },
+		func() { fs.AutoAlias() },
+		func() { fs.AllowAbbrev() },
+		func() { fs.StrictDuplicates() },
+		func() { fs.SetOverflow(OverflowClamp) },
+		func() { fs.NoPager() },
+		func() { fs.PathPlugins() },
+		func() { fs.PrintConfig() },
+		func() { fs.PrintChanged() },
+		func() { fs.DryRun(nil) },
+		func() { fs.Trace(nil) },
+		func() { fs.SetLocale("zh") },
+		func() { build.Localize("zh", "构建", "") },
+		func() { fs.PlainUsage() },
+		func() { fs.ExportEnv() },
+		func() { build.Deprecate("") },
+		func() { RestVar(build, new([]string), "files", 0, "") },
+		func() { fs.Flag("num").Deprecated("") },
+		func() { fs.Flag("num").NoOptDefault("2") },
+		func() { fs.Flag("num").Metavar("N") },
+		func() { fs.Flag("num").Secret() },
+		func() { fs.Flag("num").FromFile() },
+		func() { fs.Flag("num").FromStdin() },
+		func() { fs.Flag("num").DefaultFrom(func() int { return 2 }) },
+		func() { fs.Flag("num").NumberFormat(',', '.') },
+		func() { fs.Flag("num").Overflow(OverflowClamp) },
+		func() { fs.Flag("num").Scaled() },
+		func() { fs.Flag("verbose").Negatable() },
+		func() { fs.Flag("tags").SliceMode(SliceMode(0)) },
+		func() { fs.Flag("tags").Greedy() },
+		func() { fs.Flag("tags").ExpandEnv() },
+		func() { fs.Flag("map").MapDuplicate(DupPolicy(0)) },
+		func() { fs.Flag("timeout").Unit(time.Second) },
+		func() { fs.Flag("dir").MustExist() },
+		func() { fs.Flag("dir").CreateIfMissing() },
+		func() { fs.Flag("dir").Readable() },
+		func() { fs.Flag("dir").Writable() },
+		func() { fs.Flag("dir").Executable() },
+	}
+	for i, register := range registers {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "is sealed") {
+					t.Fatalf("register %v after seal: %v", i, r)
+				}
+			}()
+			register()
+		}()
+	}
+}
+
+func TestClone(t *testing.T) {
+	fs := New("app", "")
+	num := fs.Int('n', "num", 1, "")
+	s := fs.Stmt(func(ctx context.Context, next Handler) { next(ctx) })
+	build := s.Cmd("build", "")
+	Slice[string](build, 't', "tags", nil, "")
+	Map[string, int](build, 'm', "map", nil, "")
+	type result struct {
+		num  int
+		tags []string
+		m    map[string]int
+	}
+	results := make(chan result, 100)
+	build.Handle(func(ctx context.Context) {
+		// middleware puts the statement of the clone into the context
+		if getCmd(ctx).origin != s {
+			t.Errorf("context command: %p, want clone of %p", getCmd(ctx), s)
+		}
+		cmd := CommandFromContext(ctx)
+		results <- result{Value[int](cmd, "num"), Value[[]string](cmd, "tags"), Value[map[string]int](cmd, "map")}
+	})
+	fs.Seal()
+
+	var wg sync.WaitGroup
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := fs.Clone()
+			args := []string{"-n", fmt.Sprint(i), "build", "-t", fmt.Sprint(i), "-m", fmt.Sprintf("k:%v", i)}
+			if _, err := c.Run(context.Background(), args...); err != nil {
+				t.Errorf("run clone %v: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	for r := range results {
+		if len(r.tags) != 1 || r.tags[0] != fmt.Sprint(r.num) || len(r.m) != 1 || r.m["k"] != r.num {
+			t.Fatalf("clone result: %+v", r)
+		}
+	}
+	if *num != 0 {
+		t.Fatalf("original value changed: %v", *num)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("clone unsealed tree")
+		}
+	}()
+	New("app", "").Clone()
+}
@@ -0,0 +1,73 @@
+package flags
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Reset：重置整个命令树所有参数的解析状态，并将变量置零，以便同一个FlagSet重复解析。
+func (fs *FlagSet) Reset() {
+	root := fs.root()
+	root.warnings = nil
+	root.reset()
+}
+
+func (fs *FlagSet) reset() {
+	for _, p := range fs.allParams() {
+		p.parsed = false
+		p.source = SourceDefault
+		reflect.ValueOf(p.ptr).Elem().SetZero()
+	}
+	for _, p := range fs.allArgs() {
+		p.parsed = false
+		p.source = SourceDefault
+		reflect.ValueOf(p.ptr).Elem().SetZero()
+	}
+	for _, cmd := range fs.cmds {
+		cmd.reset()
+	}
+}
+
+// Shell：交互式命令行模式。从stdin逐行读取命令，按SplitLine拆分后在命令树上执行，
+// 每行执行前会调用Reset重置参数。输入help查看帮助，exit或quit退出，读到EOF或ctx结束时也会退出。
+func (fs *FlagSet) Shell(ctx context.Context) error {
+	return fs.shell(ctx, fs.Stdin(), fs.Stdout(), fs.Stderr())
+}
+
+func (fs *FlagSet) shell(ctx context.Context, r io.Reader, stdout, stderr io.Writer) error {
+	sc := bufio.NewScanner(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "%v> ", fs.name)
+		if !sc.Scan() {
+			fmt.Fprintln(stdout)
+			return sc.Err()
+		}
+
+		line := strings.TrimSpace(sc.Text())
+		switch line {
+		case "":
+			continue
+		case "exit", "quit":
+			return nil
+		}
+
+		fs.Reset()
+		usage, err := fs.RunLine(ctx, line)
+		if err != nil {
+			if errors.Is(err, ErrHelp) || errors.Is(err, ErrNoExecFunc) {
+				fmt.Fprintln(stdout, usage)
+			} else {
+				fmt.Fprintln(stderr, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestShell(t *testing.T) {
+	fs := New("shell", "")
+	var got []int
+	sub := fs.Cmd("sub", "a sub command")
+	i := sub.Int('i', "int", 0, "a number value")
+	sub.Handle(func(context.Context) { got = append(got, *i) })
+
+	in := strings.NewReader("sub -i 1\n\nsub\nunknown\nhelp\nexit\nsub -i 2\n")
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	err := fs.shell(context.Background(), in, stdout, stderr)
+	if err != nil {
+		t.Fatalf("shell: %v", err)
+	}
+	if !sliceEqual(got, 1, 0) {
+		t.Fatalf("shell result: %v", got)
+	}
+	if !strings.Contains(stderr.String(), "unknown sub command: unknown") {
+		t.Fatalf("shell stderr: %q", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Commands:") {
+		t.Fatalf("shell stdout: %q", stdout.String())
+	}
+}
@@ -0,0 +1,329 @@
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromSpec：根据JSON格式的命令树描述（格式同Schema的输出）构建FlagSet，便于以数据形式描述、审阅大型命令行程序。
+// 为避免引入第三方依赖，仅支持JSON，YAML格式的描述需先转为JSON。
+// 参数变量由FromSpec创建，Handler在构建完成后通过Command按名称查找命令后设置，参数值通过Value获取，如：
+//
+//	fs, err := flags.FromSpec(r)
+//	add := fs.Command("remote add")
+//	add.Handle(func(ctx context.Context) {
+//		url := flags.Value[string](add, "url")
+//	})
+//
+// 子命令中与父命令同名、同类型的参数视为继承自父命令。
+func FromSpec(r io.Reader) (fs *FlagSet, err error) {
+	var s CommandSchema
+	if err = json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("flags: decode spec: %w", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(error)
+			if !ok {
+				panic(r)
+			}
+			fs, err = nil, e
+		}
+	}()
+	fs = New(s.Name, s.Desc)
+	fs.fromSpec(s)
+	return fs, nil
+}
+
+func (fs *FlagSet) fromSpec(s CommandSchema) {
+	for _, f := range s.Flags {
+		fs.flagFromSpec(f)
+	}
+	for _, a := range s.Args {
+		fs.argFromSpec(a)
+	}
+	if len(s.Aliases) > 0 {
+		if fs.aliases == nil {
+			fs.aliases = make(map[string][]string, len(s.Aliases))
+		}
+		for name, exp := range s.Aliases {
+			if name == "" || len(exp) == 0 {
+				panic(fmt.Errorf("flags: alias name and expansion cannot be empty"))
+			}
+			fs.aliases[name] = exp
+		}
+	}
+	for _, c := range s.Commands {
+		fs.Cmd(c.Name, c.Desc).fromSpec(c)
+	}
+}
+
+func (fs *FlagSet) flagFromSpec(f FlagSchema) {
+	var short rune
+	if f.Short != "" {
+		rs := []rune(f.Short)
+		if len(rs) != 1 {
+			panic(fmt.Errorf("flags: invalid short option: %q", f.Short))
+		}
+		short = rs[0]
+	}
+	if p := fs.lookup(f.Long); f.Long != "" && p != nil && p.owner != fs && p.short == f.Short && p.typ == f.Type {
+		return // 继承自父命令
+	}
+
+	rtyp, err := specType(f.Type)
+	if err != nil {
+		panic(fmt.Errorf("flags: option %v: %w", f.Long, err))
+	}
+	ptr := reflect.New(rtyp)
+	var p *param
+	switch {
+	case rtyp == typLocation:
+		fs.LocationVar(ptr.Interface().(**time.Location), short, f.Long, nil, f.Desc)
+		p = fs.params[len(fs.params)-1]
+	case f.Type == "percent":
+		fs.PercentVar(ptr.Interface().(*float64), short, f.Long, 0, f.Desc)
+		p = fs.params[len(fs.params)-1]
+	case f.Type == "decimal":
+		var dft *big.Rat // 由DecimalVar保证每次使用默认值时均为新的*big.Rat
+		if f.Default != nil {
+			if dft, err = parseDecimal(specString(f.Default)); err != nil {
+				panic(fmt.Errorf("flags: default value of %v: %w", f.Long, err))
+			}
+		}
+		fs.DecimalVar(ptr.Interface().(**big.Rat), short, f.Long, dft, f.Desc)
+		p = fs.params[len(fs.params)-1]
+	case f.Type == "path":
+		fs.PathVar(ptr.Interface().(*string), short, f.Long, "", f.Desc)
+		p = fs.params[len(fs.params)-1]
+	case f.Type == "dir":
+		fs.DirVar(ptr.Interface().(*string), short, f.Long, "", f.Desc)
+		p = fs.params[len(fs.params)-1]
+	case f.Type == "file":
+		// 描述中没有打开方式，只保存文件名，由Handler自行打开
+		p = fs.addVar(ptr.Interface(), short, f.Long, nil, f.Desc)
+		p.typ = "file"
+	default:
+		p = fs.addVar(ptr.Interface(), short, f.Long, nil, f.Desc)
+	}
+	fs.specDefault(p, f.Default)
+
+	flag := &Flag{fs: fs, p: p}
+	if f.Metavar != "" {
+		flag.Metavar(f.Metavar)
+	}
+	if f.Env != "" {
+		flag.Env(f.Env)
+	}
+	if f.NoOpt != "" {
+		flag.NoOptDefault(f.NoOpt)
+	}
+	if f.Negatable {
+		flag.Negatable()
+	}
+	if f.Greedy {
+		flag.Greedy()
+	}
+	if f.Secret {
+		flag.Secret()
+	}
+	if f.Mode == SliceReplace.String() {
+		flag.SliceMode(SliceReplace)
+	}
+}
+
+func (fs *FlagSet) argFromSpec(a ArgSchema) {
+	rtyp, err := specType(a.Type)
+	if err != nil {
+		panic(fmt.Errorf("flags: argument %v: %w", a.Name, err))
+	}
+	ptr := reflect.New(rtyp).Interface()
+	if a.Variadic {
+		fs.RestVar(ptr, a.Name, a.Min, a.Desc)
+		return
+	}
+	fs.ArgVar(ptr, a.Name, nil, a.Desc)
+	fs.specDefault(fs.posArgs[len(fs.posArgs)-1], a.Default)
+}
+
+// Command：按空格分隔的路径查找子命令，如fs.Command("remote add")，未找到时返回nil。
+func (fs *FlagSet) Command(path string) *FlagSet {
+	f := fs
+	for _, name := range strings.Fields(path) {
+		next := f.cmdIdx[name]
+		if next == nil {
+			return nil
+		}
+		f = next
+	}
+	return f
+}
+
+// Value：按名称获取参数或位置参数的当前值，适用于FromSpec构建的FlagSet。未找到参数或类型不符时panic。
+func Value[T any](fs *FlagSet, name string) T {
+	p := fs.lookup(name)
+	if p == nil {
+		for _, a := range fs.allArgs() {
+			if a.arg == name {
+				p = a
+				break
+			}
+		}
+	}
+	if p == nil {
+		panic(fmt.Errorf("flags: unknown option: %v", name))
+	}
+	v, ok := reflect.ValueOf(p.ptr).Elem().Interface().(T)
+	if !ok {
+		panic(fmt.Errorf("flags: option %v type %v is not %T", name, p.rtyp, v))
+	}
+	return v
+}
+
+// specDefault：将描述中的默认值按命令行格式解析后设为参数默认值。
+func (fs *FlagSet) specDefault(p *param, dft any) {
+	if dft == nil {
+		return
+	}
+	val := reflect.ValueOf(p.ptr).Elem()
+	err := fs.parseAny(p, dft, p.name())
+	if err != nil {
+		panic(fmt.Errorf("flags: default value of %v: %w", p.name(), err))
+	}
+
+	if !val.IsZero() {
+		p.dft = val.Interface()
+	}
+	val.SetZero()
+	p.parsed = false
+	p.source = SourceDefault
+}
+
+// parseAny：将JSON形式的值（string、float64、json.Number、bool、[]any、map[string]any）按命令行格式解析到参数，
+// 用于描述中的默认值及配置文件中的值。slice逐个元素解析，map按分隔符拼接后解析，struct参数按JSON解析。
+func (fs *FlagSet) parseAny(p *param, v any, arg string) error {
+	if p.kind == reflect.Struct && p.rtyp != typDateTime {
+		return fs._parseValue(newArg(jsonValue(v)), arg, p)
+	}
+	switch x := v.(type) {
+	case []any:
+		if !p.isSlice() {
+			break
+		}
+		for _, e := range x {
+			if err := fs._parseSlice(newArg(specString(e)), arg, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		if p.kind != reflect.Map {
+			break
+		}
+		pairs := make([]string, 0, len(x))
+		for k, v := range x {
+			inner, nested := v.(map[string]any)
+			if !nested || p.elem.kind != reflect.Map {
+				pairs = append(pairs, escape(k, p.sep1, p.sep2)+p.sep2+escape(specString(v), p.sep1, p.sep2))
+				continue
+			}
+			seps := []string{p.sep1, p.sep2, p.sep3}
+			for ik, iv := range inner {
+				pairs = append(pairs, escape(k, seps...)+p.sep3+escape(ik, seps...)+p.sep2+escape(specString(iv), seps...))
+			}
+		}
+		sort.Strings(pairs)
+		return fs._parseValue(newArg(strings.Join(pairs, p.sep1)), arg, p)
+	}
+	return fs._parseValue(newArg(specString(v)), arg, p)
+}
+
+func specString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case map[string]any, []any:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+var specTypes = map[string]reflect.Type{
+	"int":     reflect.TypeOf(int(0)),
+	"int8":    reflect.TypeOf(int8(0)),
+	"int16":   reflect.TypeOf(int16(0)),
+	"int32":   reflect.TypeOf(int32(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"uint":    reflect.TypeOf(uint(0)),
+	"uint8":   reflect.TypeOf(uint8(0)),
+	"uint16":  reflect.TypeOf(uint16(0)),
+	"uint32":  reflect.TypeOf(uint32(0)),
+	"uint64":  reflect.TypeOf(uint64(0)),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+	"bool":    reflect.TypeOf(false),
+	"string":  reflect.TypeOf(""),
+
+	"duration": typDuration,
+	"datetime": typDateTime,
+	"location": typLocation,
+	"mac":      typHardwareAddr,
+	"filemode": typFileMode,
+	"loglevel": typLogLevel,
+	"template": typTemplate,
+
+	// 以下类型通过专门的注册函数创建，见flagFromSpec
+	"percent": reflect.TypeOf(float64(0)),
+	"decimal": reflect.TypeOf((*big.Rat)(nil)),
+	"path":    reflect.TypeOf(""),
+	"dir":     reflect.TypeOf(""),
+	"file":    reflect.TypeOf(""),
+
+	typDuration.String():     typDuration,
+	typDateTime.String():     typDateTime,
+	typHardwareAddr.String(): typHardwareAddr,
+	typFileMode.String():     typFileMode,
+	typLogLevel.String():     typLogLevel,
+}
+
+// specType：将Schema中的类型名称转为变量类型。
+func specType(name string) (reflect.Type, error) {
+	if strings.HasPrefix(name, "datetime") {
+		return typDateTime, nil
+	}
+	if t, ok := specTypes[name]; ok {
+		return t, nil
+	}
+	if elem, ok := strings.CutPrefix(name, "[]"); ok {
+		et, err := specType(elem)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(et), nil
+	}
+	if rest, ok := strings.CutPrefix(name, "map["); ok {
+		if i := strings.IndexByte(rest, ']'); i > 0 {
+			kt, err := specType(rest[:i])
+			if err != nil {
+				return nil, err
+			}
+			vt, err := specType(rest[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			return reflect.MapOf(kt, vt), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %q", name)
+}
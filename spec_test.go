@@ -0,0 +1,128 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromSpec(t *testing.T) {
+	spec := `{
+  "name": "app",
+  "desc": "spec app",
+  "flags": [
+    {"short": "v", "long": "verbose", "type": "bool", "negatable": true},
+    {"long": "timeout", "type": "duration", "default": "3s", "env": "APP_TIMEOUT"}
+  ],
+  "aliases": {"ra": ["remote", "add"]},
+  "commands": [{
+    "name": "remote",
+    "commands": [{
+      "name": "add",
+      "desc": "add a remote",
+      "flags": [
+        {"short": "v", "long": "verbose", "type": "bool", "negatable": true},
+        {"long": "tags", "type": "[]string", "default": ["a", "b"], "mode": "replace"},
+        {"long": "limits", "type": "map[string]int", "default": {"cpu": 2}}
+      ],
+      "args": [
+        {"name": "name", "type": "string"},
+        {"name": "url", "type": "string", "default": "http://x"}
+      ]
+    }]
+  }]
+}`
+	fs, err := FromSpec(strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("from spec: %v", err)
+	}
+
+	add := fs.Command("remote add")
+	var name, url string
+	var tags []string
+	var limits map[string]int
+	add.Handle(func(context.Context) {
+		name, url = Value[string](add, "name"), Value[string](add, "url")
+		tags, limits = Value[[]string](add, "tags"), Value[map[string]int](add, "limits")
+	})
+
+	if _, err = fs.Run(context.Background(), "ra", "origin", "--tags", "c", "--no-verbose"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if name != "origin" || url != "http://x" || !sliceEqual(tags, "c") || limits["cpu"] != 2 {
+		t.Fatalf("name: %q, url: %q, tags: %q, limits: %v", name, url, tags, limits)
+	}
+	if Value[time.Duration](add, "timeout") != 3*time.Second {
+		t.Fatalf("timeout: %v", Value[time.Duration](add, "timeout"))
+	}
+
+	// 往返：Schema的输出可以被FromSpec读取
+	b, err := fs.Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	fs2, err := FromSpec(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("from schema: %v", err)
+	}
+	add2 := fs2.Command("remote add")
+	add2.Handle(func(context.Context) {})
+	if u1, u2 := add.Usage(), add2.Usage(); u1 != u2 {
+		t.Fatalf("usage:\n%v\n\nfrom schema:\n%v", u1, u2)
+	}
+
+	if _, err = FromSpec(strings.NewReader(`{"name": "x", "flags": [{"long": "a", "type": "chan int"}]}`)); err == nil ||
+		!strings.Contains(err.Error(), `unsupported type "chan int"`) {
+		t.Fatalf("unsupported type: %v", err)
+	}
+}
+
+func TestSpecTypes(t *testing.T) {
+	// types registered through dedicated functions survive a Schema round trip
+	fs := New("app", "")
+	fs.Percent(0, "rate", 0.15, "")
+	fs.Decimal(0, "price", big.NewRat(1999, 100), "")
+	fs.Path(0, "home", "/tmp", "")
+	fs.Dir(0, "workdir", "", "")
+	fs.OutputFile('o', "output", OpenCreate, "")
+	fs.Handle(func(context.Context) {})
+
+	b, err := fs.Schema()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	fs2, err := FromSpec(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("from schema: %v", err)
+	}
+	fs2.Handle(func(context.Context) {})
+	if u1, u2 := fs.Usage(), fs2.Usage(); u1 != u2 {
+		t.Fatalf("usage:\n%v\n\nfrom schema:\n%v", u1, u2)
+	}
+
+	if _, err = fs2.Run(context.Background(), "--rate", "20%", "--home", "~", "-o", "out.txt"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if v := Value[float64](fs2, "rate"); v != 0.2 {
+		t.Fatalf("rate: %v", v)
+	}
+	if v := Value[*big.Rat](fs2, "price"); v.FloatString(2) != "19.99" {
+		t.Fatalf("price: %v", v)
+	}
+	if home, _ := os.UserHomeDir(); Value[string](fs2, "home") != home {
+		t.Fatalf("home: %v", Value[string](fs2, "home"))
+	}
+	if v := Value[string](fs2, "output"); v != "out.txt" {
+		t.Fatalf("output: %v", v)
+	}
+
+	fs2.Reset()
+	if _, err = fs2.Run(context.Background(), "--workdir", "spec_test.go"); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Fatalf("workdir: %v", err)
+	}
+}
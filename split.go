@@ -0,0 +1,97 @@
+package flags
+
+import "strings"
+
+// splitEscaped：按sep分割s，以反斜杠转义的sep不作为分隔符。分割结果保留转义字符，由unescape处理。
+func splitEscaped(s, sep string) []string {
+	var parts []string
+	for {
+		before, after, found := cutEscaped(s, sep)
+		parts = append(parts, before)
+		if !found {
+			return parts
+		}
+		s = after
+	}
+}
+
+// cutEscaped：同strings.Cut，以反斜杠转义的sep不作为分隔符，用于逐个遍历元素而无需分配slice。
+func cutEscaped(s, sep string) (before, after string, found bool) {
+	for i := 0; i < len(s); {
+		if s[i] == '\\' && i+1 < len(s) {
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(s[i:], sep) {
+			return s[:i], s[i+len(sep):], true
+		}
+		i++
+	}
+	return s, "", false
+}
+
+// scratch：解析slice、map元素时复用的单值参数，避免每个元素分配arguments。
+type scratch struct {
+	args arguments
+	buf  [1]string
+}
+
+// of：以s为唯一参数值，align含义同arguments.align。
+func (sc *scratch) of(s string, align bool) *arguments {
+	sc.buf[0] = s
+	sc.args = arguments{args: sc.buf[:], align: align}
+	return &sc.args
+}
+
+// unescape：去除seps及反斜杠本身的转义，其它反斜杠原样保留。
+func unescape(s string, seps ...string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			if s[i+1] == '\\' {
+				b.WriteByte('\\')
+				i++
+				continue
+			}
+			if sep := escapedSep(s[i+1:], seps); sep != "" {
+				b.WriteString(sep)
+				i += len(sep)
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// escape：unescape的逆操作，转义s中的seps及反斜杠。
+func escape(s string, seps ...string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			b.WriteString(`\\`)
+			continue
+		}
+		if sep := escapedSep(s[i:], seps); sep != "" {
+			b.WriteByte('\\')
+			b.WriteString(sep)
+			i += len(sep) - 1
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func escapedSep(s string, seps []string) string {
+	for _, sep := range seps {
+		if sep != "" && strings.HasPrefix(s, sep) {
+			return sep
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package flags
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSplitEscaped(t *testing.T) {
+	cases := []struct {
+		s     string
+		sep   string
+		parts []string
+	}{
+		{"a,b,c", ",", []string{"a", "b", "c"}},
+		{`a\,b,c`, ",", []string{`a\,b`, "c"}},
+		{`a\\,b`, ",", []string{`a\\`, "b"}},
+		{"a::b::c", "::", []string{"a", "b", "c"}},
+		{"", ",", []string{""}},
+	}
+	for _, c := range cases {
+		if parts := splitEscaped(c.s, c.sep); !sliceEqual(parts, c.parts...) {
+			t.Fatalf("split escaped %q by %q: %q", c.s, c.sep, parts)
+		}
+	}
+
+	if before, after, found := cutEscaped(`k\:1:v:2`, ":"); before != `k\:1` || after != "v:2" || !found {
+		t.Fatalf("cut escaped: %q, %q, %v", before, after, found)
+	}
+	if before, after, found := cutEscaped(`k\:1`, ":"); before != `k\:1` || after != "" || found {
+		t.Fatalf("cut escaped: %q, %q, %v", before, after, found)
+	}
+
+	if s := unescape(`a\,b\\c\d\:`, ",", ":"); s != `a,b\c\d:` {
+		t.Fatalf("unescape: %q", s)
+	}
+	if s := escape(`a,b\c\d:`, ",", ":"); s != `a\,b\\c\\d\:` || unescape(s, ",", ":") != `a,b\c\d:` {
+		t.Fatalf("escape: %q", s)
+	}
+}
+
+func TestEscapedComposite(t *testing.T) {
+	fs := New("escape", "")
+	m := Map[string, string](fs, 'm', "map", nil, "a map of string string")
+	s := Slice[string](fs, 's', "slice", nil, "a slice of string")
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), `--map=url:http\://x\,y,dir:C\:\\`, `--slice=a\,b,c`)
+	if err != nil {
+		t.Fatalf("escape run: %v", err)
+	}
+	if !mapEqual(*m, map[string]string{"url": "http://x,y", "dir": `C:\`}) {
+		t.Fatalf("escape map result: %q", *m)
+	}
+	if !sliceEqual(*s, "a,b", "c") {
+		t.Fatalf("escape slice result: %q", *s)
+	}
+}
@@ -0,0 +1,47 @@
+package flags
+
+import (
+	"io"
+	"os"
+)
+
+// SetStdio：设置标准输入、输出及错误输出，用于打印帮助信息、Shell、读取"-"参数值、Reader及Writer参数、
+// 外部插件、--print-config等内置参数的输出，以及警告、UseLogging默认的日志输出等。
+// 为nil时使用os.Stdin、os.Stdout、os.Stderr，默认均为nil。测试或嵌入其它程序时可用于捕获和驱动输入输出。
+// 输出不是*os.File时不使用分页。仅根命令有效。
+func (fs *FlagSet) SetStdio(stdin io.Reader, stdout, stderr io.Writer) *FlagSet {
+	fs.unsealed()
+	root := fs.root()
+	root.stdin, root.stdout, root.stderr = stdin, stdout, stderr
+	return fs
+}
+
+// Stdio：SetStdio设置的标准输入、输出及错误输出，未设置的为nil，便于临时替换后恢复。
+func (fs *FlagSet) Stdio() (stdin io.Reader, stdout, stderr io.Writer) {
+	root := fs.root()
+	return root.stdin, root.stdout, root.stderr
+}
+
+// Stdin：标准输入，见SetStdio。
+func (fs *FlagSet) Stdin() io.Reader {
+	if r := fs.root().stdin; r != nil {
+		return r
+	}
+	return os.Stdin
+}
+
+// Stdout：标准输出，见SetStdio。
+func (fs *FlagSet) Stdout() io.Writer {
+	if w := fs.root().stdout; w != nil {
+		return w
+	}
+	return os.Stdout
+}
+
+// Stderr：标准错误输出，见SetStdio。
+func (fs *FlagSet) Stderr() io.Writer {
+	if w := fs.root().stderr; w != nil {
+		return w
+	}
+	return os.Stderr
+}
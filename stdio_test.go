@@ -0,0 +1,47 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStdio(t *testing.T) {
+	fs := New("app", "stdio app")
+	in := fs.Reader('i', "input", "-", "input file")
+	out := fs.Writer('o', "output", "-", "output file")
+	fs.Str(0, "old", "", "")
+	fs.Flag("old").Deprecated("use --new")
+	var got string
+	fs.Handle(func(context.Context) {
+		b, _ := io.ReadAll(*in)
+		got = string(b)
+		(*out).Write(bytes.ToUpper(b))
+	})
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	fs.SetStdio(strings.NewReader("hello"), stdout, stderr)
+	if _, err := fs.Run(context.Background(), "--old", "x"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got != "hello" || stdout.String() != "HELLO" || !strings.Contains(stderr.String(), "use --new") {
+		t.Fatalf("stdin: %q, stdout: %q, stderr: %q", got, stdout, stderr)
+	}
+
+	// help is printed to the injected stdout without a pager
+	stdout.Reset()
+	code := -1
+	fs.SetExiter(ExiterFunc(func(c int) { code = c }))
+	fs.exit(fs.main(context.Background(), []string{"-h"}, fs.Stderr()))
+	if code != ExitOK || !strings.HasPrefix(stdout.String(), "app - stdio app") {
+		t.Fatalf("help: %v, %q", code, stdout)
+	}
+
+	fs.SetStdio(nil, nil, nil)
+	if fs.Stdin() != os.Stdin || fs.Stdout() != os.Stdout || fs.Stderr() != os.Stderr {
+		t.Fatalf("default stdio")
+	}
+}
@@ -0,0 +1,33 @@
+package flags
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Trace：将解析过程中的每一步输出到w，包括读取的参数、参数赋值、子命令及别名展开、默认值及环境变量等，
+// 用于排查复杂命令行为何被如此解析。w为nil时关闭。Flag.Secret标记的参数值会被隐藏。
+func (fs *FlagSet) Trace(w io.Writer) *FlagSet {
+	fs.unsealed()
+	fs.root().trace = w
+	return fs
+}
+
+func (fs *FlagSet) tracing() bool {
+	return fs.root().trace != nil
+}
+
+func (fs *FlagSet) tracef(format string, args ...any) {
+	if w := fs.root().trace; w != nil {
+		fmt.Fprintf(w, "%v: %v\n", fs.fullName(), fmt.Sprintf(format, args...))
+	}
+}
+
+// traceValue：参数当前值，用于Trace输出。
+func (p *param) traceValue() string {
+	if p.secret {
+		return jsonString("******")
+	}
+	return jsonValue(configValue(p, reflect.ValueOf(p.ptr).Elem()))
+}
@@ -0,0 +1,38 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	b := new(bytes.Buffer)
+	fs := New("app", "trace").Trace(b)
+	fs.Str('o', "output", "out.txt", "")
+	fs.Str(NoShort, "token", "", "")
+	fs.Flag("token").Secret()
+	fs.Alias("ls", "list -a")
+	list := fs.Cmd("list", "")
+	list.Bool('a', "all", false, "")
+	list.Handle(func(context.Context) {})
+
+	if _, err := fs.Run(context.Background(), "--token=x", "ls"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	exp := `app: token "--token=x"
+app: set --token = "******" (--token=x)
+app: token "ls"
+app: default --output = "out.txt"
+app: alias ls = ["list" "-a"]
+app: token "list"
+app: default --output = "out.txt"
+app: sub command list
+app list: token "-a"
+app list: set --all = true (-a)
+app list: default --output = "out.txt"
+`
+	if b.String() != exp {
+		t.Fatalf("trace:\n%s\nexpected:\n%s", b, exp)
+	}
+}
@@ -0,0 +1,66 @@
+package flags
+
+import "fmt"
+
+// UnknownFlagHandler：处理无法识别的参数，用于支持无法预先注册的动态参数，如"-Dkey=value"。
+// cmd为当前解析的命令，arg为无法识别的参数，rest为其后尚未解析的参数。
+// 返回值n为从rest中额外消耗的参数个数；返回ErrUnknownOption时按默认方式报告未知参数。
+type UnknownFlagHandler func(cmd *FlagSet, arg string, rest []string) (n int, err error)
+
+// OnUnknownFlag：设置无法识别的参数的处理函数，子命令未设置时使用父命令的处理函数。
+func (fs *FlagSet) OnUnknownFlag(fn UnknownFlagHandler) *FlagSet {
+	fs.unsealed()
+	fs.onUnknownFlag = fn
+	return fs
+}
+
+// unknownFlag：交由OnUnknownFlag设置的处理函数处理无法识别的参数，未设置时报错。
+func (fs *FlagSet) unknownFlag(args *arguments, arg string) error {
+	var fn UnknownFlagHandler
+	for f := fs; f != nil && fn == nil; f = f.parent {
+		fn = f.onUnknownFlag
+	}
+	if fn == nil {
+		return fmt.Errorf("%v: %w: %v", fs.name, ErrUnknownOption, arg)
+	}
+
+	rest := args.args[args.idx:]
+	n, err := fn(fs, arg, rest[:len(rest):len(rest)])
+	if err != nil {
+		return fmt.Errorf("%v: %w: %v", fs.name, err, arg)
+	}
+	if n < 0 || n > len(rest) {
+		return fmt.Errorf("%v: option %v: consumed %v of %v args", fs.name, arg, n, len(rest))
+	}
+	fs.tracef("unknown option %v handled, consumed %q", arg, rest[:n])
+	args.idx += n
+	return nil
+}
+
+// UnknownCommandHandler：处理无法识别的子命令，用于动态解析子命令，如从数据库加载的命令、外部插件等。
+// cmd为当前解析的命令，name为无法识别的子命令名称。返回的子命令通常由cmd.Cmd动态注册，解析将在该子命令中继续；
+// 返回nil时按默认方式处理，即报告未知子命令（Parse时作为位置参数）。
+type UnknownCommandHandler func(cmd *FlagSet, name string) (*FlagSet, error)
+
+// OnUnknownCommand：设置无法识别的子命令的处理函数，在别名、外部插件之后调用，子命令未设置时使用父命令的处理函数。
+func (fs *FlagSet) OnUnknownCommand(fn UnknownCommandHandler) *FlagSet {
+	fs.unsealed()
+	fs.onUnknownCmd = fn
+	return fs
+}
+
+// unknownCommand：交由OnUnknownCommand设置的处理函数解析子命令，未设置或未解析时返回nil。
+func (fs *FlagSet) unknownCommand(name string) (*FlagSet, error) {
+	var fn UnknownCommandHandler
+	for f := fs; f != nil && fn == nil; f = f.parent {
+		fn = f.onUnknownCmd
+	}
+	if fn == nil {
+		return nil, nil
+	}
+	cmd, err := fn(fs, name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: sub command %v: %w", fs.name, name, err)
+	}
+	return cmd, nil
+}
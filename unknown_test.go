@@ -0,0 +1,106 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOnUnknownFlag(t *testing.T) {
+	fs := New("app", "")
+	verbose := fs.Bool('v', "verbose", false, "verbose")
+
+	defines := make(map[string]string)
+	fs.OnUnknownFlag(func(cmd *FlagSet, arg string, rest []string) (int, error) {
+		if kv, ok := strings.CutPrefix(arg, "-D"); ok {
+			k, v, _ := strings.Cut(kv, "=")
+			defines[k] = v
+			return 0, nil
+		}
+		if arg == "--define" && len(rest) > 0 {
+			k, v, _ := strings.Cut(rest[0], "=")
+			defines[k] = v
+			return 1, nil
+		}
+		if arg == "--bad" {
+			return 0, errors.New("bad option")
+		}
+		return 0, ErrUnknownOption
+	})
+
+	var ran bool
+	fs.Cmd("build", "").Handle(func(ctx context.Context) {
+		ran = true
+	})
+
+	// subcommand inherits the handler
+	_, err := fs.Run(context.Background(), "-Dos=linux", "build", "--define", "arch=amd64", "-v")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !ran || !*verbose || defines["os"] != "linux" || defines["arch"] != "amd64" {
+		t.Fatalf("ran: %v, verbose: %v, defines: %v", ran, *verbose, defines)
+	}
+
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "build", "--unknown")
+	if !errors.Is(err, ErrUnknownOption) || err.Error() != "build: unknown option: --unknown" {
+		t.Fatalf("fallback error: %v", err)
+	}
+
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "--bad", "build")
+	if err == nil || err.Error() != "app: bad option: --bad" {
+		t.Fatalf("handler error: %v", err)
+	}
+}
+
+func TestOnUnknownCommand(t *testing.T) {
+	fs := New("app", "")
+	var calls int
+	var got []string
+	fs.OnUnknownCommand(func(cmd *FlagSet, name string) (*FlagSet, error) {
+		calls++
+		switch name {
+		case "deploy", "rollback":
+			c := cmd.Cmd(name, "loaded "+name)
+			targets := Rest[string](c, "targets", 0, "")
+			c.Handle(func(ctx context.Context) {
+				got = append(got, name+":"+strings.Join(*targets, ","))
+			})
+			return c, nil
+		case "broken":
+			return nil, errors.New("load failed")
+		}
+		return nil, nil
+	})
+
+	_, err := fs.Run(context.Background(), "deploy", "a", "b")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	// registered command is found without calling the handler again
+	fs.Reset()
+	_, err = fs.Run(context.Background(), "deploy")
+	if err != nil {
+		t.Fatalf("run again: %v", err)
+	}
+	if calls != 1 || !sliceEqual(got, "deploy:a,b", "deploy:") {
+		t.Fatalf("calls: %v, got: %q", calls, got)
+	}
+
+	_, err = fs.Run(context.Background(), "unknown")
+	if !errors.Is(err, ErrUnknownCommand) || err.Error() != "app: unknown sub command: unknown" {
+		t.Fatalf("fallback error: %v", err)
+	}
+	_, err = fs.Run(context.Background(), "broken")
+	if err == nil || err.Error() != "app: sub command broken: load failed" {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	m, err := fs.Parse("rollback", "x")
+	if err != nil || m.Path != "app rollback" || !sliceEqual(m.Args, "x") {
+		t.Fatalf("parse: %+v, %v", m, err)
+	}
+}
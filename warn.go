@@ -0,0 +1,50 @@
+package flags
+
+import (
+	"fmt"
+)
+
+// Warning：解析及执行过程中的非致命问题，如使用了已弃用的命令或参数、map参数的重复key被覆盖等。
+type Warning struct {
+	Command string // 命令完整名称
+	Option  string // 相关参数，如"--labels"，与参数无关时为空
+	Message string // 警告内容
+}
+
+func (w Warning) String() string {
+	if w.Option == "" {
+		return fmt.Sprintf("%v: %v", w.Command, w.Message)
+	}
+	return fmt.Sprintf("%v: option %v: %v", w.Command, w.Option, w.Message)
+}
+
+// OnWarning：设置警告回调，每产生一个警告调用一次。
+// 未设置时，弃用提示输出到stderr，其它警告仅记录，可通过Warnings获取。仅根命令有效。
+func (fs *FlagSet) OnWarning(fn func(Warning)) *FlagSet {
+	fs.unsealed()
+	fs.root().onWarning = fn
+	return fs
+}
+
+// Warnings：最近一次Run、Exec、Parse或ReloadConfig过程中产生的警告。
+func (fs *FlagSet) Warnings() []Warning {
+	return fs.root().warnings
+}
+
+// warn：记录警告，并调用OnWarning设置的回调。
+func (fs *FlagSet) warn(option, format string, args ...any) {
+	w := Warning{Command: fs.fullName(), Option: option, Message: fmt.Sprintf(format, args...)}
+	root := fs.root()
+	root.warnings = append(root.warnings, w)
+	if root.onWarning != nil {
+		root.onWarning(w)
+	}
+}
+
+// notice：同warn，未设置OnWarning时同时输出到stderr，用于需要用户看到的警告，如弃用提示。
+func (fs *FlagSet) notice(option, format string, args ...any) {
+	fs.warn(option, format, args...)
+	if fs.root().onWarning == nil {
+		fmt.Fprintf(fs.Stderr(), "warning: %v\n", fs.root().warnings[len(fs.root().warnings)-1])
+	}
+}
@@ -0,0 +1,51 @@
+package flags
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	fs := New("app", "warnings")
+	var streamed []string
+	fs.OnWarning(func(w Warning) { streamed = append(streamed, w.String()) })
+	fs.Str('o', "out", "", "output file")
+	fs.Str(NoShort, "output", "", "output file")
+	fs.Flag("out").Deprecated("use --output instead")
+	labels := Map[string, string](fs, 'l', "labels", nil, "labels")
+	sub := fs.Cmd("old", "").Deprecate("")
+	sub.Handle(func(context.Context) {})
+	fs.Handle(func(context.Context) {})
+
+	_, err := fs.Run(context.Background(), "--out", "x", "-l", "a:1,a:2")
+	if err != nil {
+		t.Fatalf("warnings run: %v", err)
+	}
+	if (*labels)["a"] != "2" {
+		t.Fatalf("warnings labels: %v", *labels)
+	}
+	exp := []string{
+		"app: option --out: option is deprecated: use --output instead",
+		"app: option -l: duplicated key a overwritten",
+	}
+	if !sliceEqual(streamed, exp...) {
+		t.Fatalf("streamed warnings: %q", streamed)
+	}
+	if ws := fs.Warnings(); len(ws) != 2 || ws[1].Option != "-l" || ws[1].Command != "app" {
+		t.Fatalf("warnings: %v", ws)
+	}
+
+	fs.Reset()
+	streamed = nil
+	if _, err = fs.Run(context.Background(), "old"); err != nil {
+		t.Fatalf("warnings run: %v", err)
+	}
+	if ws := fs.Warnings(); len(ws) != 1 || ws[0].String() != "app old: command is deprecated" {
+		t.Fatalf("warnings after reset: %v", ws)
+	}
+
+	if usage := fs.Usage(); !strings.Contains(usage, "-o, --out string (deprecated: use --output instead)") {
+		t.Fatalf("deprecated option usage: %v", usage)
+	}
+}
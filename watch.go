@@ -0,0 +1,155 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"time"
+)
+
+// ReloadConfig：重新读取配置文件（见ConfigFiles、XDGConfig），并重新计算当前命令可见参数中
+// 未通过命令行参数、环境变量及--last赋值的参数值：配置项存在时使用配置值，配置项被删除时恢复默认值。
+// 返回值发生变化的参数。读取或解析出错时所有参数保持原值。
+// 参数值直接写入注册时绑定的变量，同时重置Warnings，应在读取参数的goroutine中调用，或由调用方加锁，见WatchConfig。
+func (fs *FlagSet) ReloadConfig() ([]FlagInfo, error) {
+	root := fs.root()
+	root.warnings = nil
+	prev, prevStamp := root.configVals, root.configStamp
+	if err := fs.loadConfig(); err != nil {
+		root.configVals, root.configStamp = prev, prevStamp
+		return nil, err
+	}
+
+	type saved struct {
+		p      *param
+		val    reflect.Value
+		source Source
+	}
+	var list []saved
+	restore := func() {
+		for _, s := range list {
+			reflect.ValueOf(s.p.ptr).Elem().Set(s.val)
+			s.p.source = s.source
+		}
+		root.configVals, root.configStamp = prev, prevStamp
+	}
+
+	var err error
+	fs.eachParam(func(p *param) bool {
+		if p.source != SourceConfig && p.source != SourceDefault {
+			return true
+		}
+		elem := reflect.ValueOf(p.ptr).Elem()
+		old := reflect.New(p.rtyp).Elem()
+		old.Set(elem)
+		list = append(list, saved{p: p, val: old, source: p.source})
+
+		if e, ok := root.configVals[p.configKey()]; ok {
+			elem.SetZero()
+			if err = fs.parseAny(p, e.val, e.file+":"+p.configKey()); err != nil {
+				return false
+			}
+			p.source = SourceConfig
+			return true
+		}
+		p.source = SourceDefault
+		switch {
+		case p.setDft != nil:
+			p.setDft(p.ptr)
+		case p.dftFn.IsValid():
+			// 延迟计算的默认值可能依赖其它参数，待其它参数更新后再计算
+		case p.dft != nil:
+			elem.Set(reflect.ValueOf(p.dft))
+		default:
+			elem.SetZero()
+		}
+		return true
+	})
+	if err != nil {
+		restore()
+		return nil, err
+	}
+
+	for _, s := range list {
+		if s.p.source == SourceDefault && s.p.dftFn.IsValid() {
+			reflect.ValueOf(s.p.ptr).Elem().Set(s.p.dftFn.Call(nil)[0])
+		}
+	}
+
+	var changed []FlagInfo
+	for _, s := range list {
+		if reflect.DeepEqual(s.val.Interface(), reflect.ValueOf(s.p.ptr).Elem().Interface()) {
+			continue
+		}
+		if s.p.source == SourceConfig {
+			e := root.configVals[s.p.configKey()]
+			fs.flagParsed(e.file+":"+s.p.configKey(), s.p)
+		}
+		changed = append(changed, s.p.info())
+	}
+	return changed, nil
+}
+
+// WatchConfig：每隔interval（不大于0时为1秒）检查一次配置文件，文件被修改、创建或删除时调用apply，
+// 由apply在合适的时机调用reload（即ReloadConfig）重新读取配置并更新参数值，适用于长期运行的服务在Handler中使用。
+// WatchConfig所在的goroutine只检查文件状态，不修改参数值，apply可在加锁后调用reload，
+// 或将reload交给读取参数的goroutine执行，如：
+//
+//	serve.Handle(func(ctx context.Context) {
+//		var mu sync.Mutex // 保护参数变量
+//		go flags.CommandFromContext(ctx).WatchConfig(ctx, time.Second, func(reload func() ([]flags.FlagInfo, error)) {
+//			mu.Lock()
+//			defer mu.Unlock()
+//			changed, err := reload()
+//			if err != nil {
+//				log.Printf("reload config: %v", err)
+//				return
+//			}
+//			for _, f := range changed {
+//				log.Printf("config %v changed to %v", f.Long, f.Value)
+//			}
+//		})
+//		...
+//	})
+//
+// WatchConfig一直阻塞到ctx结束，返回ctx.Err()。
+func (fs *FlagSet) WatchConfig(ctx context.Context, interval time.Duration, apply func(reload func() ([]FlagInfo, error))) error {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	stamp := fs.root().configStamp
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		cur := statConfig(fs.root().ConfigPaths())
+		if reflect.DeepEqual(cur, stamp) {
+			continue
+		}
+		stamp = cur
+		if apply != nil {
+			apply(fs.ReloadConfig)
+		}
+	}
+}
+
+// fileStamp：配置文件的修改时间及大小，文件不存在时为零值。
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// statConfig：各配置文件的fileStamp，用于判断配置文件是否变化。
+func statConfig(paths []string) []fileStamp {
+	stamps := make([]fileStamp, len(paths))
+	for i, path := range paths {
+		if st, err := os.Stat(path); err == nil {
+			stamps[i] = fileStamp{mod: st.ModTime(), size: st.Size()}
+		}
+	}
+	return stamps
+}
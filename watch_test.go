@@ -0,0 +1,75 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeTestFile(t, path, `{"level": 2, "output": "a.txt", "name": "x"}`)
+
+	fs := New("app", "").ConfigFiles(path)
+	serve := fs.Cmd("serve", "")
+	level := serve.Int('l', "level", 1, "")
+	output := serve.Str('o', "output", "out.txt", "")
+	name := serve.Str('n', "name", "dft", "")
+	serve.Handle(func(ctx context.Context) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		// reloads run in the handler goroutine, the watcher only reports file changes
+		reloads := make(chan func() ([]FlagInfo, error))
+		done := make(chan error, 1)
+		go func() {
+			done <- CommandFromContext(ctx).WatchConfig(ctx, 5*time.Millisecond, func(reload func() ([]FlagInfo, error)) {
+				select {
+				case reloads <- reload:
+				case <-ctx.Done():
+				}
+			})
+		}()
+
+		// the flag given on the command line is not overridden, removed keys fall back to defaults
+		writeTestFile(t, path, `{"level": 3, "output": "b.txt"}`)
+		future := time.Now().Add(time.Hour)
+		if err := os.Chtimes(path, future, future); err != nil {
+			t.Errorf("chtimes: %v", err)
+			return
+		}
+		select {
+		case reload := <-reloads:
+			changed, err := reload()
+			if err != nil || len(changed) != 2 || changed[0].Long != "level" || changed[0].Value != "3" ||
+				changed[1].Long != "name" || changed[1].Value != "dft" || changed[1].Source != SourceDefault {
+				t.Errorf("changed: %+v, %v", changed, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("no change notified")
+		}
+		cancel()
+		if err := <-done; err != context.Canceled {
+			t.Errorf("watch: %v", err)
+		}
+	})
+
+	if _, err := fs.Run(context.Background(), "serve", "-o", "c.txt"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if *level != 3 || *output != "c.txt" || *name != "dft" {
+		t.Fatalf("level: %v, output: %v, name: %v", *level, *output, *name)
+	}
+
+	// invalid config keeps the previous values
+	writeTestFile(t, path, `{"level": "x"}`)
+	if changed, err := serve.ReloadConfig(); err == nil || changed != nil || *level != 3 || *name != "dft" {
+		t.Fatalf("reload invalid: %v, %v, level: %v", changed, err, *level)
+	}
+	writeTestFile(t, path, `{"level": 3}`)
+	if changed, err := serve.ReloadConfig(); err != nil || len(changed) != 0 {
+		t.Fatalf("reload unchanged: %v, %v", changed, err)
+	}
+}
@@ -0,0 +1,70 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configNames：标准位置下依次查找的配置文件名。
+var configNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// XDGConfig：从标准位置查找应用app的配置文件，app为空时使用根命令名称。按优先级从高到低依次为：
+// $XDG_CONFIG_HOME/<app>/（未设置时为~/.config/<app>/）、~/.config/<app>/、$XDG_CONFIG_DIRS中各目录下的<app>/
+// （未设置时为/etc/xdg/<app>/）、/etc/<app>/，每个目录下依次查找config.json、config.yaml、config.yml、config.toml。
+// 查找在每次解析时进行，环境变量通过SetLookupEnv设置的函数读取。ConfigFiles设置的文件优先级更高，其余同ConfigFiles。
+// 仅根命令有效。
+func (fs *FlagSet) XDGConfig(app string) *FlagSet {
+	fs.unsealed()
+	root := fs.root()
+	if app == "" {
+		app = root.name
+	}
+	root.xdgApp = app
+	return fs
+}
+
+// ConfigPaths：按优先级从高到低列出所有候选配置文件路径，包括ConfigFiles设置的路径及XDGConfig的标准位置，
+// 不检查文件是否存在。
+func (fs *FlagSet) ConfigPaths() []string {
+	root := fs.root()
+	paths := append([]string(nil), root.configPaths...)
+	if root.xdgApp == "" {
+		return paths
+	}
+
+	var dirs []string
+	home, ok := root.getenv("HOME")
+	if !ok || home == "" {
+		home, _ = os.UserHomeDir()
+	}
+	if dir, ok := root.getenv("XDG_CONFIG_HOME"); ok && filepath.IsAbs(dir) {
+		dirs = append(dirs, dir)
+	}
+	if home != "" {
+		dirs = append(dirs, filepath.Join(home, ".config"))
+	}
+	sysDirs := "/etc/xdg"
+	if s, ok := root.getenv("XDG_CONFIG_DIRS"); ok && s != "" {
+		sysDirs = s
+	}
+	for _, dir := range strings.Split(sysDirs, ":") {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	dirs = append(dirs, "/etc")
+
+	seen := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		dir = filepath.Join(dir, root.xdgApp)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		for _, name := range configNames {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	return paths
+}
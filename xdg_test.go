@@ -0,0 +1,47 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestXDGConfig(t *testing.T) {
+	home, xdgHome, xdgDir := t.TempDir(), t.TempDir(), t.TempDir()
+	env := map[string]string{"HOME": home, "XDG_CONFIG_HOME": xdgHome, "XDG_CONFIG_DIRS": xdgDir + ":relative"}
+	fs := New("app", "").XDGConfig("flags-xdg-test")
+	fs.SetLookupEnv(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+	output := fs.Str('o', "output", "out.txt", "")
+	level := fs.Int(0, "level", 1, "")
+
+	paths := fs.ConfigPaths()
+	dirs := []string{xdgHome, filepath.Join(home, ".config"), xdgDir, "/etc"}
+	if len(paths) != len(dirs)*len(configNames) {
+		t.Fatalf("config paths: %q", paths)
+	}
+	for i, dir := range dirs {
+		if want := filepath.Join(dir, "flags-xdg-test", "config.json"); paths[i*len(configNames)] != want {
+			t.Fatalf("config path %v: %v, want %v", i, paths[i*len(configNames)], want)
+		}
+	}
+
+	// the user config overrides the system-wide one
+	for dir, content := range map[string]string{
+		filepath.Join(xdgHome, "flags-xdg-test"): "output: user.txt\n",
+		filepath.Join(xdgDir, "flags-xdg-test"):  "output: system.txt\nlevel: 3\n",
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		writeTestFile(t, filepath.Join(dir, "config.yaml"), content)
+	}
+	if _, err := fs.Parse(); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if *output != "user.txt" || *level != 3 {
+		t.Fatalf("output: %v, level: %v", *output, *level)
+	}
+}